@@ -26,17 +26,61 @@ import (
 )
 
 type Option struct {
-	Restore bool   `short:"b" long:"restore" description:"Restore deleted file"`
-	Config  string `long:"config" description:"Path to config file" default:""`
+	Restore   bool   `short:"b" long:"restore" description:"Restore deleted file"`
+	List      bool   `long:"list" description:"List deleted files without the interactive UI"`
+	Undo      string `long:"undo" value-name:"RUNID" description:"Restore all files deleted by the last gomi run, or by RUNID (see --list)" optional:"yes" optional-value:"last"`
+	Config    string `long:"config" description:"Path to config file" default:""`
+	Stats     bool   `long:"stats" description:"Show how much space the trash uses (with --prune, also what pruning would reclaim)"`
+	Empty     bool   `long:"empty" description:"Permanently delete everything in the trash, across all trash directories"`
+	Retention bool   `long:"enforce-retention" description:"Permanently delete the oldest files beyond core.trash.retention (also done after put, once per check_interval)"`
+	FilesFrom string `long:"files-from" value-name:"FILE" description:"Also trash the files listed in FILE, one per line (- reads from stdin)"`
+	Null      bool   `short:"0" long:"null" description:"Names in --files-from are separated by NUL bytes, as printed by find -print0"`
+	Workers   int    `long:"workers" value-name:"N" description:"Move up to N files at a time (default: core.put.workers, or the number of CPUs)"`
+	DryRun    bool   `long:"dry-run" description:"Print what would be done without changing anything (put, restore, --prune and --empty)"`
+	Report    string `long:"report" value-name:"FORMAT" description:"Print the result of put, restore and --prune for each file to stdout, one JSON object per line" choice:"json"`
+
+	RestoreBy RestoreOption `group:"Restore Options"`
+	EmptyBy   EmptyOption   `group:"Empty Options"`
+	Output    OutputOption  `group:"Output Options"`
+	Meta      MetaOption    `group:"Meta Options"`
+	Rm        RmOption      `group:"Compatible (rm) Options"`
+}
+
+// RestoreOption selects files to restore without the interactive UI
+type RestoreOption struct {
+	Path       string `long:"restore-path" value-name:"PATH" description:"Restore the most recent file deleted from PATH"`
+	Glob       string `long:"restore-glob" value-name:"PATTERN" description:"Restore files whose original path (or name, if the pattern has no slash) matches a glob"`
+	ID         string `long:"restore-id" value-name:"ID" description:"Restore the file with the given ID (see --list)"`
+	Version    int    `long:"restore-version" value-name:"N" description:"Pick the Nth most recent copy of the same path (1 is the newest)"`
+	DeletedAt  string `long:"deleted-at" value-name:"TIME" description:"Pick the copy deleted at TIME (e.g., 2024-06-15 or 2024-06-15T10:30:00)"`
+	OnConflict string `long:"on-conflict" description:"What to do when the destination exists, instead of asking" choice:"skip" choice:"overwrite" choice:"rename" choice:"fail"`
+	RestoreTo  string `long:"restore-to" value-name:"DIR" description:"Restore under DIR instead of the original location, keeping the layout of the restored files"`
+}
+
+// EmptyOption selects the trash directories emptied by --empty
+type EmptyOption struct {
+	Storage string `long:"storage" description:"Only empty trash directories of this storage" choice:"xdg" choice:"legacy"`
+	Mount   string `long:"mount" value-name:"PATH" description:"Only empty trash directories located under PATH (e.g., /media/usb)"`
+}
 
-	Meta MetaOption `group:"Meta Options"`
-	Rm   RmOption   `group:"Compatible (rm) Options"`
+// OutputOption controls what --list prints and how. --stats only uses
+// Format.
+type OutputOption struct {
+	Format  string `long:"format" description:"Output format" default:"table" choice:"table" choice:"json" choice:"jsonl" choice:"csv" choice:"tsv"`
+	Sort    string `long:"sort" description:"Sort key (date: newest first, size: largest first)" default:"date" choice:"date" choice:"name" choice:"path" choice:"size"`
+	Reverse bool   `long:"reverse" description:"Reverse the sort order"`
+	Name    string `long:"name" description:"Only list files whose name matches a glob pattern"`
+	Within  string `long:"within" description:"Only list files deleted within a duration (e.g., 7d)"`
+	MinSize string `long:"min-size" description:"Only list files at least this large (e.g., 10MB)"`
+	MaxSize string `long:"max-size" description:"Only list files at most this large (e.g., 1GB)"`
 }
 
 type MetaOption struct {
-	Version bool      `short:"V" long:"version" description:"Show version"`
-	Debug   string    `long:"debug" description:"View debug logs" optional-value:"full" optional:"yes" choice:"full" choice:"live"`
-	Prune   PruneArgs `long:"prune" description:"Prunes trash by removing orphaned metadata and items older than a specified duration (e.g., 30d,orphans)"`
+	Version    bool      `short:"V" long:"version" description:"Show version"`
+	Debug      string    `long:"debug" description:"View debug logs" optional-value:"full" optional:"yes" choice:"full" choice:"live"`
+	Prune      PruneArgs `long:"prune" description:"Prunes trash by removing orphaned metadata, or items selected by age, size, name and original path (e.g., 30d,size>1GB,name:*.iso,from:~/Downloads,keep-last=3,older=30d,orphans,orphaned-data,adopt)"`
+	Completion string    `long:"completion" value-name:"SHELL" description:"Print a shell completion script" choice:"bash" choice:"zsh" choice:"fish"`
+	Doctor     string    `long:"doctor" description:"Check trash consistency and optionally repair problems" optional-value:"check" optional:"yes" choice:"check" choice:"fix"`
 }
 
 type PruneArgs []string
@@ -49,13 +93,29 @@ func (s *PruneArgs) UnmarshalFlag(value string) error {
 
 // RmOption provides compatibility with rm command options
 // https://man7.org/linux/man-pages/man1/rm.1.html
+//
+// Most of them only take effect with core.rm_compat: strict, otherwise
+// they are accepted and ignored so that gomi can stand in for rm.
+//
+// As in rm, the last of -f, -i, -I and --interactive wins. Their flags are
+// functions, which go-flags calls in order, setting the fields at the end.
 type RmOption struct {
-	Interactive bool `short:"i" description:"(dummy) prompt before every removal"`
-	Recursive   bool `short:"r" long:"recursive" description:"(dummy) remove directories and their contents recursively"`
-	Recursive2  bool `short:"R" description:"(dummy) same as -r"`
-	Force       bool `short:"f" long:"force" description:"ignore nonexistent files, never prompt (effective with --prune)"`
-	Directory   bool `short:"d" long:"dir" description:"(dummy) remove empty directories"`
-	Verbose     bool `short:"v" long:"verbose" description:"(dummy) explain what is being done"`
+	InteractiveFlag     func()       `short:"i" description:"prompt before every removal (strict)"`
+	InteractiveOnceFlag func()       `short:"I" description:"prompt once before removing more than three files, or when removing recursively (strict)"`
+	InteractiveWhenFlag func(string) `long:"interactive" value-name:"WHEN" description:"prompt according to WHEN: never, once (-I), or always (-i) (strict)" optional:"yes" optional-value:"always" choice:"never" choice:"no" choice:"none" choice:"once" choice:"always" choice:"yes"`
+	Recursive           bool         `short:"r" long:"recursive" description:"remove directories and their contents recursively (strict)"`
+	Recursive2          bool         `short:"R" description:"same as -r"`
+	ForceFlag           func()       `short:"f" long:"force" description:"ignore nonexistent files, never prompt (effective with --prune and --empty)"`
+	Directory           bool         `short:"d" long:"dir" description:"remove empty directories (strict)"`
+	Verbose             bool         `short:"v" long:"verbose" description:"explain what is being done"`
+	PreserveRoot        string       `long:"preserve-root" value-name:"all" description:"do not remove '/' (always on); with 'all', also refuse arguments on a different device from their parent (strict)" optional:"yes" optional-value:"root" choice:"root" choice:"all"`
+	OneFileSystem       bool         `long:"one-file-system" description:"refuse to remove a directory containing another file system (strict)"`
+
+	// Set by the flag functions, see bindFlags
+	Interactive     bool   `no-flag:"yes"`
+	InteractiveOnce bool   `no-flag:"yes"`
+	InteractiveWhen string `no-flag:"yes"`
+	Force           bool   `no-flag:"yes"`
 }
 
 // Prompter abstracts user interaction for confirmation and input.
@@ -64,6 +124,7 @@ type Prompter interface {
 	Confirm(prompt string) bool
 	ConfirmYes(prompt string) bool
 	InputFilename(file *trash.File) (string, error)
+	InputDirectory() (string, error)
 }
 
 type CLI struct {
@@ -73,6 +134,10 @@ type CLI struct {
 	runID    string
 	trash    trash.Trash
 	prompter Prompter
+	progress *putProgress
+	report   *reporter
+	guard    *pathGuard
+	rules    *ruleSet
 }
 
 var runID = sync.OnceValue(func() string {
@@ -80,12 +145,22 @@ var runID = sync.OnceValue(func() string {
 	return id
 })
 
+// ErrExtraArgs is returned when a command that takes no file arguments is
+// given some. Flags with an optional value only take it as --flag=value,
+// so "--undo RUNID" leaves RUNID as an argument.
+var ErrExtraArgs = errors.New("unexpected arguments")
+
 // Run is the main entry point for the CLI
 func Run(v Version) error {
 	env.Init()
 
 	opt, args, err := parseOptions(v)
 	if err != nil {
+		// The parser printed the error. rm exits with 1 on an invalid
+		// command line, rather than the usage status of gomi.
+		if cfg, loadErr := config.Load(opt.Config); loadErr == nil && cfg.Core.RmCompat == rmCompatStrict {
+			return ErrReported
+		}
 		return err
 	}
 	if opt == nil {
@@ -105,7 +180,23 @@ func Run(v Version) error {
 		return err
 	}
 
-	t, err := newTrashManager(cfg)
+	// Undo must see the whole batch, and --stats, --empty and the retention
+	// policy every file, including files hidden from -b
+	if opt.Undo != "" || opt.Stats || opt.Empty || opt.Retention || (retentionEnabled(cfg) && opt.runsPut()) {
+		cfg.History = config.History{}
+	}
+
+	var progress *putProgress
+	if showProgress(opt, cfg) {
+		progress = newPutProgress(os.Stderr)
+	}
+
+	hooks := newHookRunner(cfg.Core.Hooks, runID(), progress)
+
+	trashConfig := newTrashConfig(cfg)
+	trashConfig.OnCopy = progress.onCopy()
+	trashConfig.Hook = hooks.hookFunc()
+	t, err := newTrashManager(trashConfig)
 	if err != nil {
 		return err
 	}
@@ -124,6 +215,10 @@ func Run(v Version) error {
 		runID:    runID(),
 		trash:    t,
 		prompter: &uiPrompter{},
+		progress: progress,
+	}
+	if opt.Report != "" {
+		cli.report = newReporter(os.Stdout)
 	}
 
 	if err := cli.Run(args); err != nil {
@@ -140,6 +235,9 @@ func (c CLI) Run(args []string) error {
 		fmt.Fprint(os.Stdout, c.version)
 		return nil
 
+	case c.option.Meta.Completion != "":
+		return writeCompletion(os.Stdout, c.option.Meta.Completion, c.version.AppName)
+
 	case c.option.Meta.Debug != "":
 		if !c.config.Logging.Enabled {
 			return fmt.Errorf("logging is not enabled in config")
@@ -150,30 +248,76 @@ func (c CLI) Run(args []string) error {
 			c.option.Meta.Debug == debug.LiveMode,
 		)
 
+	// --stats takes --prune arguments to estimate what they would reclaim
+	case c.option.Stats:
+		return c.Stats()
+
 	case len(c.option.Meta.Prune) > 0:
 		return c.Prune(c.option.Meta.Prune)
 
+	case c.option.Empty:
+		return c.Empty()
+
+	case c.option.Retention:
+		return c.EnforceRetention()
+
+	case c.option.Meta.Doctor != "":
+		if len(args) > 0 {
+			return fmt.Errorf("--doctor: %w %q, give the mode as --doctor=%s", ErrExtraArgs, args, doctorFix)
+		}
+		return c.Doctor(c.option.Meta.Doctor == doctorFix)
+
+	case c.option.RestoreBy.Path != "" || c.option.RestoreBy.Glob != "" || c.option.RestoreBy.ID != "":
+		return c.RestoreSelected()
+
 	case c.option.Restore:
 		return c.Restore()
 
+	case c.option.Undo != "":
+		if len(args) > 0 {
+			return fmt.Errorf("--undo: %w %q, give the run as --undo=RUNID", ErrExtraArgs, args)
+		}
+		return c.Undo(c.option.Undo)
+
+	case c.option.List:
+		return c.List()
+
 	}
 
 	return c.Put(args)
 }
 
+// runsPut reports whether Run moves files to trash, i.e. none of the
+// other commands of Run is given
+func (o *Option) runsPut() bool {
+	m := o.Meta
+	return !m.Version && m.Completion == "" && m.Debug == "" && !o.Stats &&
+		len(m.Prune) == 0 && !o.Empty && !o.Retention && m.Doctor == "" &&
+		o.RestoreBy.Path == "" && o.RestoreBy.Glob == "" && o.RestoreBy.ID == "" &&
+		!o.Restore && o.Undo == "" && !o.List
+}
+
 // parseOptions parses and returns command line options
 func parseOptions(v Version) (*Option, []string, error) {
 	var opt Option
+	opt.Rm.bindFlags()
 	parser := flags.NewParser(&opt, flags.Default)
 	parser.Name = v.AppName
-	parser.Usage = "[-b | files...]"
+	parser.Usage = "[-b | --list | --stats | --undo | --empty | [--files-from FILE] [--] files...]"
+	parser.CompletionHandler = func(items []flags.Completion) {
+		printCompletions(os.Stdout, parser, os.Args[1:], items)
+	}
 
 	args, err := parser.Parse()
 	if err != nil {
 		if flags.WroteHelp(err) {
 			return nil, nil, nil
 		}
-		return nil, nil, err
+		// The options parsed so far, such as --config, are still returned
+		return &opt, nil, err
+	}
+	if isCompleting() {
+		return nil, nil, nil // candidates were printed
 	}
 
 	// On Windows, the shell does not expand wildcards,
@@ -247,16 +391,24 @@ func determineLogLevel(level string) log.Level {
 	}
 }
 
-// newTrashManager creates and configures the trash manager
-func newTrashManager(cfg *config.Config) (*trash.Manager, error) {
-	trashConfig := trash.Config{
+// newTrashConfig returns the trash configuration of cfg. OnCopy and Hook
+// are left for the caller to set.
+func newTrashConfig(cfg *config.Config) trash.Config {
+	return trash.Config{
 		Strategy:     trash.Strategy(cfg.Core.Trash.Strategy),
 		HomeFallback: cfg.Core.Trash.HomeFallback,
 		History:      cfg.History,
 		GomiDir:      cfg.Core.Trash.GomiDir,
-		RunID:        runID(), // for backward compatibility (legacy strategy)
+		RunID:        runID(), // recorded with every trashed file for --undo
+
+		CreateExternalTrash: cfg.Core.Trash.External.Create,
+		ExternalMounts:      cfg.Core.Trash.External.Mounts,
 	}
+}
 
+// newTrashManager creates the trash manager, with the storages of the
+// strategy of trashConfig
+func newTrashManager(trashConfig trash.Config) (*trash.Manager, error) {
 	var opts []trash.ManagerOption
 
 	// If gomi_dir is explicitly set and strategy is auto, use legacy storage
@@ -301,3 +453,4 @@ type uiPrompter struct{}
 func (p *uiPrompter) Confirm(prompt string) bool                  { return ui.Confirm(prompt) }
 func (p *uiPrompter) ConfirmYes(prompt string) bool               { return ui.ConfirmYes(prompt) }
 func (p *uiPrompter) InputFilename(f *trash.File) (string, error) { return ui.InputFilename(f) }
+func (p *uiPrompter) InputDirectory() (string, error)             { return ui.InputDirectory() }
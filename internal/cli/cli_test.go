@@ -2,8 +2,10 @@ package cli
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -91,16 +93,16 @@ func TestDetermineLogLevel(t *testing.T) {
 	}
 }
 
-func TestIsForbiddenPath(t *testing.T) {
+func TestForbiddenPaths(t *testing.T) {
 	cli := &CLI{
 		config: &config.Config{
 			Core: config.Core{
 				Trash: config.TrashConfig{
-					ForbiddenPaths: []string{
-						"/",
-						"/etc",
-						"/usr",
-						"$HOME/.gomi",
+					ForbiddenPaths: []config.ForbiddenPath{
+						{Path: "/"},
+						{Path: "/etc"},
+						{Path: "/usr"},
+						{Path: "$HOME/.gomi"},
 					},
 				},
 			},
@@ -123,16 +125,16 @@ func TestIsForbiddenPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			got := cli.isForbiddenPath(tt.path)
+			got := cli.forbiddenPaths().check(tt.path).mode != ""
 			if got != tt.want {
-				t.Errorf("isForbiddenPath(%q) = %v, want %v", tt.path, got, tt.want)
+				t.Errorf("check(%q) protected = %v, want %v", tt.path, got, tt.want)
 			}
 		})
 	}
 }
 
 func TestSyncStringSlice(t *testing.T) {
-	s := &syncStringSlice{}
+	s := &syncSlice[string]{}
 
 	// Empty initially
 	if got := s.Get(); len(got) != 0 {
@@ -161,7 +163,7 @@ func TestSyncStringSlice(t *testing.T) {
 }
 
 func TestSyncStringSlice_Concurrent(t *testing.T) {
-	s := &syncStringSlice{}
+	s := &syncSlice[string]{}
 	done := make(chan struct{})
 
 	// Concurrent writes
@@ -280,6 +282,19 @@ func TestPrune_OrphansWithOtherArgs(t *testing.T) {
 	}
 }
 
+func TestPrune_OrphanedDataWithOtherArgs(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig()}
+	for _, arg := range []string{"orphaned-data", "adopt"} {
+		err := cli.Prune([]string{arg, "30d"})
+		if err == nil {
+			t.Fatalf("Prune with %s + duration should return error", arg)
+		}
+		if !strings.Contains(err.Error(), "cannot be combined") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
 func TestPrune_InvalidDuration(t *testing.T) {
 	cli := CLI{config: config.NewDefaultConfig()}
 	err := cli.Prune([]string{"xyz"})
@@ -344,3 +359,65 @@ func TestFilterFiles(t *testing.T) {
 		t.Errorf("Name = %q, want %q", filtered[0].Name, "exists.txt")
 	}
 }
+
+func TestCLI_Run_DoctorArgs(t *testing.T) {
+	cli := CLI{
+		option: Option{Meta: MetaOption{Doctor: "check"}},
+		config: config.NewDefaultConfig(),
+	}
+
+	// "gomi --doctor fix" must not silently run the check only
+	if err := cli.Run([]string{"fix"}); !errors.Is(err, ErrExtraArgs) {
+		t.Errorf("Run() error = %v, want %v", err, ErrExtraArgs)
+	}
+}
+
+func TestDoctor_Unsupported(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig()}
+	err := cli.Doctor(false)
+	if !errors.Is(err, ErrDoctorUnsupported) {
+		t.Errorf("Doctor() error = %v, want %v", err, ErrDoctorUnsupported)
+	}
+}
+
+func TestDoctor_ReportsProblems(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("XDG trash is not used on Windows")
+	}
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+	m, err := trash.NewManager(trash.Config{
+		Strategy:       trash.StrategyXDG,
+		HomeFallback:   true,
+		ForceHomeTrash: true,
+	}, trash.WithStorage(xdg.NewStorage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orphan := filepath.Join(dataDir, "Trash", "info", "gone.txt.trashinfo")
+	info := "[Trash Info]\nPath=/tmp/gone.txt\nDeletionDate=2024-06-15T10:30:00\n"
+	if err := os.WriteFile(orphan, []byte(info), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := CLI{config: config.NewDefaultConfig(), trash: m}
+	cli.option.Rm.Force = true
+
+	if err := cli.Doctor(false); !errors.Is(err, ErrDoctorProblems) {
+		t.Fatalf("Doctor(false) error = %v, want %v", err, ErrDoctorProblems)
+	}
+	if _, err := os.Stat(orphan); err != nil {
+		t.Fatalf("Doctor(false) should not modify the trash: %v", err)
+	}
+
+	if err := cli.Doctor(true); err != nil {
+		t.Fatalf("Doctor(true) error = %v", err)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("orphaned metadata should be removed, stat error = %v", err)
+	}
+	if err := cli.Doctor(false); err != nil {
+		t.Errorf("Doctor(false) after fix error = %v", err)
+	}
+}
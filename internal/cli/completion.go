@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/utils/shell"
+)
+
+// completionEnv is set by the completion scripts when they ask gomi for
+// candidates. go-flags then completes the arguments instead of parsing them.
+const completionEnv = "GO_FLAGS_COMPLETION"
+
+// pruneWords are the --prune arguments offered by completion
+var pruneWords = []string{
+	"orphans", "orphaned-data", "adopt",
+	"keep-last=", "older=",
+	"size>", "size<", "name:", "name~", "from:",
+}
+
+// durationUnits are appended to a number being completed for --prune
+var durationUnits = []string{"h", "d", "w", "m", "y"}
+
+// completionScripts are the scripts printed by --completion. Each of them
+// runs gomi with completionEnv set and falls back to file names when gomi
+// has no candidates.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for {{.}}
+# Load with: source <({{.}} --completion bash)
+
+_{{.}}() {
+    local cur=${COMP_WORDS[COMP_CWORD]}
+    local line=${COMP_LINE:0:COMP_POINT}
+    local -a words
+    read -ra words <<< "$line"
+    [[ $line == *[[:space:]] ]] && words+=("")
+
+    local IFS=$'\n'
+    COMPREPLY=($(` + completionEnv + `=1 {{.}} "${words[@]:1}" 2>/dev/null))
+
+    # Bash splits --opt=value into several words, so only the part
+    # after the last word break is replaced
+    local prefix=${words[${#words[@]}-1]%"$cur"}
+    COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == *[=:~,\<\>] ]]; then
+        compopt -o nospace
+    fi
+}
+
+complete -o default -F _{{.}} {{.}}
+`,
+
+	"zsh": `#compdef {{.}}
+# Load with: source <({{.}} --completion zsh)
+
+_{{.}}() {
+    local -a items nospace
+    items=(${(f)"$(` + completionEnv + `=1 {{.}} "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#items} == 0 )); then
+        _files
+        return
+    fi
+    nospace=(${(M)items:#*[=:~,\<\>]})
+    items=(${items:#*[=:~,\<\>]})
+    compadd -S '' -- "${nospace[@]}"
+    compadd -- "${items[@]}"
+}
+
+compdef _{{.}} {{.}}
+`,
+
+	"fish": `# fish completion for {{.}}
+# Load with: {{.}} --completion fish | source
+
+function __{{.}}_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    set -l cur (commandline -ct)
+    env ` + completionEnv + `=1 {{.}} $args "$cur" 2>/dev/null
+end
+
+complete -c {{.}} -a '(__{{.}}_complete)'
+`,
+}
+
+// writeCompletion writes the completion script of sh for appName
+func writeCompletion(w io.Writer, sh, appName string) error {
+	script, ok := completionScripts[sh]
+	if !ok {
+		return fmt.Errorf("unsupported shell: %s", sh)
+	}
+	return template.Must(template.New(sh).Parse(script)).Execute(w, appName)
+}
+
+// printCompletions prints the candidates for the last of args, one per
+// line. go-flags completes option names and values of Completer types;
+// everything else, such as choices and --prune arguments, is filled in
+// here.
+func printCompletions(w io.Writer, parser *flags.Parser, args []string, items []flags.Completion) {
+	var candidates []string
+	for _, item := range items {
+		candidates = append(candidates, item.Item)
+	}
+	if len(candidates) == 0 {
+		candidates = completeOptionValue(parser, args)
+	}
+	for _, c := range candidates {
+		fmt.Fprintln(w, c)
+	}
+}
+
+// completeOptionValue returns the candidates for the value of an option
+// being completed, either as "--opt=value" or as "--opt value"
+func completeOptionValue(parser *flags.Parser, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	last := args[len(args)-1]
+
+	var opt *flags.Option
+	var prefix, match string
+	if name, value, ok := strings.Cut(last, "="); ok && strings.HasPrefix(name, "--") {
+		opt = parser.FindOptionByLongName(strings.TrimPrefix(name, "--"))
+		prefix, match = name+"=", value
+	} else if len(args) > 1 && !strings.HasPrefix(last, "-") {
+		prev := args[len(args)-2]
+		if name, ok := strings.CutPrefix(prev, "--"); ok {
+			opt = parser.FindOptionByLongName(name)
+		} else if len(prev) == 2 && prev[0] == '-' {
+			opt = parser.FindOptionByShortName(rune(prev[1]))
+		}
+		// Optional values must be given as --opt=value
+		if opt != nil && opt.OptionalArgument {
+			opt = nil
+		}
+		match = last
+	}
+	if opt == nil || opt.Field().Type.Kind() == reflect.Bool {
+		return nil
+	}
+
+	var values []string
+	switch {
+	case len(opt.Choices) > 0:
+		values = opt.Choices
+	case opt.LongName == "prune":
+		values = completePrune(match)
+	case opt.LongName == "restore-path" || opt.LongName == "restore-glob":
+		values = completeTrashPaths(match, configArg(args[:len(args)-1]))
+	}
+
+	var candidates []string
+	for _, v := range values {
+		if strings.HasPrefix(v, match) {
+			candidates = append(candidates, prefix+v)
+		}
+	}
+	return candidates
+}
+
+// completePrune completes the last of the comma-separated --prune
+// arguments in match
+func completePrune(match string) []string {
+	head, last := "", match
+	if i := strings.LastIndex(match, ","); i >= 0 {
+		head, last = match[:i+1], match[i+1:]
+	}
+
+	words := pruneWords
+	if last != "" && strings.Trim(last, "0123456789") == "" {
+		words = nil
+		for _, unit := range durationUnits {
+			words = append(words, last+unit)
+		}
+	}
+
+	var values []string
+	for _, w := range words {
+		values = append(values, head+w)
+	}
+	return values
+}
+
+// configArg returns the value of --config given in args, if any
+func configArg(args []string) string {
+	var path string
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			path = value
+		} else if arg == "--config" && i+1 < len(args) {
+			path = args[i+1]
+		}
+	}
+	return path
+}
+
+// completeTrashPaths returns the original paths of the trashed files,
+// using the config file at configPath, or the default one if empty.
+// The trash is opened read-only. A leading ~ in match is kept in the
+// candidates.
+func completeTrashPaths(match, configPath string) []string {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		slog.Debug("failed to load config for completion", "error", err)
+		return nil
+	}
+	if err := setLogger(cfg); err != nil {
+		return nil
+	}
+	trashConfig := newTrashConfig(cfg)
+	trashConfig.ReadOnly = true
+	t, err := newTrashManager(trashConfig)
+	if err != nil {
+		slog.Debug("failed to open trash for completion", "error", err)
+		return nil
+	}
+	files, err := t.List()
+	if err != nil {
+		slog.Debug("failed to list trash for completion", "error", err)
+		return nil
+	}
+
+	home := ""
+	if strings.HasPrefix(match, "~") {
+		if expanded, err := shell.ExpandHome("~"); err == nil {
+			home = expanded
+		}
+	}
+
+	var paths []string
+	for _, f := range files {
+		path := f.GetOriginalPath()
+		if home != "" {
+			rest, ok := strings.CutPrefix(path, home)
+			if !ok {
+				continue
+			}
+			path = "~" + rest
+		}
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// isCompleting reports whether gomi was run by a completion script
+func isCompleting() bool {
+	return os.Getenv(completionEnv) != ""
+}
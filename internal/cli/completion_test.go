@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/trash/legacy"
+)
+
+func TestCompleteOptionValue(t *testing.T) {
+	var opt Option
+	parser := flags.NewParser(&opt, flags.Default)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"choices after =", []string{"--debug="}, []string{"--debug=full", "--debug=live"}},
+		{"choices as next argument", []string{"--format", "js"}, []string{"json", "jsonl"}},
+		{"optional value needs =", []string{"--debug", ""}, nil},
+		{"prune words", []string{"--prune=or"}, []string{"--prune=orphans", "--prune=orphaned-data"}},
+		{"prune duration units", []string{"--prune", "30"}, []string{"30h", "30d", "30w", "30m", "30y"}},
+		{"prune after comma", []string{"--prune=30d,keep"}, []string{"--prune=30d,keep-last="}},
+		{"bool option", []string{"--list", ""}, nil},
+		{"unknown option", []string{"--nope="}, nil},
+		{"positional", []string{"file"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := completeOptionValue(parser, tt.args)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("completeOptionValue(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigArg(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"none", []string{"--restore-path"}, ""},
+		{"with =", []string{"--config=/a.yaml", "--restore-path"}, "/a.yaml"},
+		{"as next argument", []string{"--config", "/a.yaml", "--restore-path"}, "/a.yaml"},
+		{"last wins", []string{"--config=/a.yaml", "--config=/b.yaml"}, "/b.yaml"},
+		{"after --", []string{"--", "--config=/a.yaml"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configArg(tt.args); got != tt.want {
+				t.Errorf("configArg(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteTrashPaths_ReadOnly(t *testing.T) {
+	gomiDir := t.TempDir()
+	s, err := legacy.NewStorage(trash.Config{GomiDir: gomiDir, Strategy: trash.StrategyLegacy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(src); err != nil {
+		t.Fatal(err)
+	}
+	backup := filepath.Join(gomiDir, "history.json.backup")
+	if err := os.Remove(backup); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := fmt.Sprintf("core:\n  trash:\n    strategy: legacy\n    gomi_dir: %s\n", gomiDir)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := completeTrashPaths("", configPath); !slices.Equal(got, []string{src}) {
+		t.Errorf("completeTrashPaths() = %q, want %q from --config", got, src)
+	}
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		t.Error("completion should not write to the trash")
+	}
+}
+
+func TestWriteCompletion(t *testing.T) {
+	for _, sh := range []string{"bash", "zsh", "fish"} {
+		t.Run(sh, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeCompletion(&buf, sh, "gomi"); err != nil {
+				t.Fatalf("writeCompletion() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), completionEnv+"=1 gomi") {
+				t.Errorf("script does not run gomi for candidates:\n%s", buf.String())
+			}
+		})
+	}
+
+	if err := writeCompletion(&bytes.Buffer{}, "tcsh", "gomi"); err == nil {
+		t.Error("writeCompletion() should fail for an unsupported shell")
+	}
+}
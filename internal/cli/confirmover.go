@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/dustin/go-humanize"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/ui/table"
+	"github.com/babarot/gomi/internal/utils/fs"
+)
+
+// summaryLargest is the number of items listed in the summary of a large put
+const summaryLargest = 5
+
+// putSummary describes what a put would move, for core.put.confirm_over
+type putSummary struct {
+	items []putItem // one per argument that exists
+
+	count int   // files and directories, including the contents of directories
+	size  int64 // bytes of regular files
+	depth int   // depth of the deepest directory tree
+
+	// partial is set when a limit was crossed before all names were read
+	partial bool
+}
+
+// putItem is an argument of a put
+type putItem struct {
+	arg   string
+	path  string
+	count int
+	size  int64
+}
+
+// confirmPut asks before moving files to trash if they cross the limits
+// of core.put.confirm_over. The names are measured as they are read, and
+// only those read until a limit is crossed are held in memory. It returns
+// the files to move, or false if the user declined.
+func (c *CLI) confirmPut(files iter.Seq2[string, error]) (iter.Seq2[string, error], bool, error) {
+	next, stop := iter.Pull2(files)
+	guard := c.forbiddenPaths()
+
+	var (
+		names   []string
+		summary putSummary
+		over    []string
+	)
+	for len(over) == 0 {
+		name, err, ok := next()
+		if !ok {
+			break
+		}
+		if err != nil {
+			stop()
+			return nil, false, err
+		}
+		names = append(names, name)
+		summary.add(guard, name)
+		over = summary.over(c.config.Core.Put.ConfirmOver)
+	}
+	rest := func(yield func(string, error) bool) {
+		defer stop()
+		for _, name := range names {
+			if !yield(name, nil) {
+				return
+			}
+		}
+		for {
+			name, err, ok := next()
+			if !ok || !yield(name, err) {
+				return
+			}
+		}
+	}
+	if len(over) == 0 {
+		return rest, true, nil
+	}
+
+	// Whether names are left is only known by reading one more
+	name, err, ok := next()
+	if ok {
+		names = append(names, name)
+		summary.partial = true
+	}
+	if err != nil {
+		stop()
+		return nil, false, err
+	}
+	if c.option.FilesFrom == "-" {
+		stop()
+		return nil, false, fmt.Errorf("%w: %s (use -f to move them anyway)", ErrPromptFromStdin, strings.Join(over, ", "))
+	}
+
+	c.printPutSummary(os.Stderr, summary, over)
+	if !c.prompter.Confirm("Move them to trash?") {
+		stop()
+		return nil, false, nil
+	}
+	return rest, true, nil
+}
+
+// add measures arg the way Put would see it. Arguments that do not exist
+// or that Put refuses are left out, as they are never moved.
+func (s *putSummary) add(guard *pathGuard, arg string) {
+	expandedPath, err := expandPath(arg)
+	if err != nil {
+		return
+	}
+	if unsafe, err := fs.IsUnsafePath(expandedPath); err != nil || unsafe {
+		return
+	}
+	path, err := filepath.Abs(expandedPath)
+	if err != nil {
+		return
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+
+	// The walk of a directory is shared with the protection check here
+	// and the one of Put
+	item := putItem{arg: arg, path: path, count: 1}
+	if fi.IsDir() {
+		tree := guard.scanTree(path, true)
+		item.count, item.size = tree.count, tree.size
+		s.depth = max(s.depth, tree.depth)
+	} else if fi.Mode().IsRegular() {
+		item.size = fi.Size()
+	}
+	if guard.check(path).mode == protectRefuse {
+		return
+	}
+
+	s.items = append(s.items, item)
+	s.count += item.count
+	s.size += item.size
+}
+
+// over returns the limits crossed by s
+func (s putSummary) over(limits config.ConfirmOverConfig) []string {
+	var over []string
+	if limits.Count > 0 && s.count > limits.Count {
+		over = append(over, fmt.Sprintf("%d items > %d", s.count, limits.Count))
+	}
+	if limits.Size != "" {
+		if size, err := units.FromHumanSize(limits.Size); err == nil && s.size > size {
+			over = append(over, fmt.Sprintf("%s > %s", humanize.Bytes(uint64(s.size)), humanize.Bytes(uint64(size))))
+		}
+	}
+	if limits.Depth > 0 && s.depth > limits.Depth {
+		over = append(over, fmt.Sprintf("depth %d > %d", s.depth, limits.Depth))
+	}
+	return over
+}
+
+// printPutSummary prints the largest items of s, the trash directories
+// they would go to, and whether they would be copied across devices
+func (c *CLI) printPutSummary(w io.Writer, s putSummary, over []string) {
+	atLeast := ""
+	if s.partial {
+		atLeast = "at least "
+	}
+	fmt.Fprintf(w, "About to move %s%d items (%s) to trash: %s\n",
+		atLeast, s.count, humanize.Bytes(uint64(s.size)), strings.Join(over, ", "))
+
+	largest := slices.Clone(s.items)
+	slices.SortStableFunc(largest, func(a, b putItem) int {
+		return cmp.Compare(b.size, a.size)
+	})
+	var rows [][]string
+	for _, item := range largest[:min(len(largest), summaryLargest)] {
+		rows = append(rows, []string{humanize.Bytes(uint64(item.size)), strconv.Itoa(item.count), item.arg})
+	}
+	if len(largest) > summaryLargest {
+		rows = append(rows, []string{"", "", fmt.Sprintf("... and %d more", len(largest)-summaryLargest)})
+	}
+	table.Render(w, []string{"Size", "Items", "Path"}, rows)
+
+	planner, ok := c.trash.(trash.Planner)
+	if !ok {
+		return
+	}
+	var (
+		dirs        []string
+		crossDevice bool
+	)
+	for _, item := range s.items {
+		plan, err := planner.PlanPut(item.path)
+		if err != nil {
+			continue
+		}
+		if dir := fmt.Sprintf("%s (%s)", plan.TrashDir, plan.Backend); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+		crossDevice = crossDevice || plan.CrossDevice
+	}
+	for _, dir := range dirs {
+		fmt.Fprintf(w, "Trash: %s\n", dir)
+	}
+	if crossDevice {
+		fmt.Fprintln(w, "Cross-device: yes (files will be copied, then removed)")
+	} else {
+		fmt.Fprintln(w, "Cross-device: no")
+	}
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/babarot/gomi/internal/config"
+)
+
+// writeTree creates files of the given sizes under dir
+func writeTree(t *testing.T, dir string, files map[string]int) {
+	t.Helper()
+	for name, size := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSummarizePut(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]int{
+		"a.txt":         100,
+		"tree/b.txt":    200,
+		"tree/sub/c.md": 300,
+	})
+
+	cli := CLI{config: config.NewDefaultConfig()}
+	var s putSummary
+	for _, name := range []string{"a.txt", "tree", "missing"} {
+		s.add(cli.forbiddenPaths(), filepath.Join(dir, name))
+	}
+
+	// tree, tree/b.txt, tree/sub and tree/sub/c.md, plus a.txt
+	if s.count != 5 || s.size != 600 || s.depth != 2 || len(s.items) != 2 {
+		t.Errorf("summary = %d items, %d bytes, depth %d, %d args; want 5, 600, 2, 2",
+			s.count, s.size, s.depth, len(s.items))
+	}
+
+	tests := []struct {
+		name   string
+		limits config.ConfirmOverConfig
+		want   int
+	}{
+		{"no limits", config.ConfirmOverConfig{}, 0},
+		{"within limits", config.ConfirmOverConfig{Count: 5, Size: "1KB", Depth: 2}, 0},
+		{"count", config.ConfirmOverConfig{Count: 4}, 1},
+		{"size", config.ConfirmOverConfig{Size: "0KB"}, 1},
+		{"all", config.ConfirmOverConfig{Count: 1, Size: "0KB", Depth: 1}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.over(tt.limits); len(got) != tt.want {
+				t.Errorf("over() = %q, want %d limits", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPut_ConfirmOver(t *testing.T) {
+	tests := []struct {
+		name       string
+		count      int
+		answer     bool
+		force      bool
+		dryRun     bool
+		wantPut    int
+		wantPrompt bool
+	}{
+		{"under the limit", 10, false, false, false, 3, false},
+		{"confirmed", 2, true, false, false, 3, true},
+		{"declined", 2, false, false, false, 0, true},
+		{"bypassed with -f", 2, false, true, false, 3, false},
+		{"not asked with --dry-run", 2, false, false, true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, map[string]int{"a": 1, "b": 1, "c": 1})
+
+			cfg := config.NewDefaultConfig()
+			cfg.Core.Put.ConfirmOver.Count = tt.count
+			ft := &fakeTrash{}
+			p := &fakePrompter{answer: tt.answer}
+			cli := CLI{config: cfg, trash: ft, prompter: p}
+			cli.option.Rm.Force = tt.force
+			cli.option.DryRun = tt.dryRun
+
+			args := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+			if err := cli.Put(args); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if len(ft.put) != tt.wantPut {
+				t.Errorf("put %v, want %d files", ft.put, tt.wantPut)
+			}
+			if got := len(p.prompts) > 0; got != tt.wantPrompt {
+				t.Errorf("prompted = %v, want %v", got, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestConfirmPut_ReadsUntilOver(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := range 10 {
+		name := filepath.Join(dir, strconv.Itoa(i))
+		writeTree(t, dir, map[string]int{strconv.Itoa(i): 1})
+		names = append(names, name)
+	}
+
+	tests := []struct {
+		name     string
+		answer   bool
+		wantRead int
+	}{
+		// 3 items cross the limit, and one more shows that names are left
+		{"declined", false, 4},
+		{"confirmed", true, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Core.Put.ConfirmOver.Count = 2
+			cli := CLI{config: cfg, prompter: &fakePrompter{answer: tt.answer}}
+
+			var read int
+			files := func(yield func(string, error) bool) {
+				for _, name := range names {
+					read++
+					if !yield(name, nil) {
+						return
+					}
+				}
+			}
+			rest, ok, err := cli.confirmPut(files)
+			if err != nil || ok != tt.answer {
+				t.Fatalf("confirmPut() = %v, %v, want %v", ok, err, tt.answer)
+			}
+			var got []string
+			if ok {
+				for name := range rest {
+					got = append(got, name)
+				}
+			}
+			if read != tt.wantRead {
+				t.Errorf("read %d names, want %d", read, tt.wantRead)
+			}
+			if ok && !slices.Equal(got, names) {
+				t.Errorf("files = %q, want %q", got, names)
+			}
+		})
+	}
+}
+
+func TestPut_ConfirmOverFromStdin(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]int{"a": 1, "b": 1})
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(filepath.Join(dir, "a") + "\n" + filepath.Join(dir, "b") + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = stdin })
+
+	cfg := config.NewDefaultConfig()
+	cfg.Core.Put.ConfirmOver.Count = 1
+	ft := &fakeTrash{}
+	cli := CLI{config: cfg, trash: ft, prompter: &fakePrompter{answer: true}}
+	cli.option.FilesFrom = "-"
+
+	err = cli.Put(nil)
+	if !errors.Is(err, ErrPromptFromStdin) || !strings.Contains(err.Error(), "2 items > 1") {
+		t.Errorf("Put() error = %v, want %v with the crossed limit", err, ErrPromptFromStdin)
+	}
+	if len(ft.put) != 0 {
+		t.Errorf("put %v, want nothing", ft.put)
+	}
+}
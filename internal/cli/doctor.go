@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/fatih/color"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+const doctorFix = "fix"
+
+var (
+	// ErrDoctorUnsupported is returned when the trash cannot be audited
+	ErrDoctorUnsupported = errors.New("trash does not support consistency checks")
+
+	// ErrDoctorProblems is returned when problems remain after the check,
+	// so that scheduled runs exit with a non-zero status
+	ErrDoctorProblems = errors.New("trash has consistency problems")
+)
+
+// Doctor audits every storage for consistency problems and prints them.
+// With fix, it repairs the problems that can be repaired automatically.
+// It returns an error whenever a problem remains.
+func (c *CLI) Doctor(fix bool) error {
+	slog.Debug("checking trash consistency", "fix", fix)
+
+	checker, ok := c.trash.(trash.Checker)
+	if !ok {
+		return fmt.Errorf("doctor: %w", ErrDoctorUnsupported)
+	}
+
+	issues, checkErr := checker.Check()
+	if checkErr != nil {
+		slog.Error("failed to check storage", "error", checkErr)
+	}
+
+	if len(issues) == 0 {
+		if checkErr != nil {
+			return fmt.Errorf("doctor: %w", checkErr)
+		}
+		fmt.Println("No problems found.")
+		return nil
+	}
+
+	var fixable int
+	for _, issue := range issues {
+		fmt.Printf("%s %s\n    %s\n",
+			color.New(color.FgHiYellow).Sprintf("[%s]", issue.Kind),
+			issue.Path,
+			issue.Detail)
+		if issue.Fix != nil {
+			fixable++
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Found %d problems (%d can be fixed automatically).\n", len(issues), fixable)
+
+	if !fix || fixable == 0 {
+		if fixable > 0 {
+			fmt.Println("Run 'gomi --doctor=fix' to repair them.")
+		}
+		return fmt.Errorf("doctor: %w", errors.Join(ErrDoctorProblems, checkErr))
+	}
+
+	// Confirm repair unless forced
+	if !c.option.Rm.Force {
+		if !c.prompter.Confirm(fmt.Sprintf("Are you sure you want to fix %d problems?", fixable)) {
+			fmt.Println("Operation canceled.")
+			return fmt.Errorf("doctor: %w", ErrDoctorProblems)
+		}
+	}
+
+	var failedFixes []string
+	for _, issue := range issues {
+		if issue.Fix == nil {
+			continue
+		}
+		slog.Debug("fixing problem", "kind", issue.Kind, "path", issue.Path)
+		if err := issue.Fix(); err != nil {
+			slog.Error("failed to fix problem", "kind", issue.Kind, "path", issue.Path, "error", err)
+			failedFixes = append(failedFixes, issue.Path)
+		}
+	}
+
+	if len(failedFixes) > 0 {
+		fmt.Printf("Failed to fix %d problems:\n", len(failedFixes))
+		for _, path := range failedFixes {
+			fmt.Println("-", path)
+		}
+	}
+	fmt.Printf("Successfully fixed %d problems.\n", fixable-len(failedFixes))
+
+	if len(failedFixes) > 0 || fixable < len(issues) || checkErr != nil {
+		return fmt.Errorf("doctor: %w", errors.Join(ErrDoctorProblems, checkErr))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// ErrDryRunUnsupported is returned when the trash cannot tell where
+// files would go without moving them
+var ErrDryRunUnsupported = errors.New("the trash backend does not support --dry-run")
+
+// printPlan prints an action that --dry-run did not perform
+func printPlan(format string, args ...any) {
+	fmt.Printf("would "+format+"\n", args...)
+}
+
+// planPut returns where path would be moved to by Put, in the storage set
+// by rule if any, and prints it unless the plan goes to --report
+func (c *CLI) planPut(arg, path string, rule *putRule) (*trash.Plan, error) {
+	var (
+		plan *trash.Plan
+		err  error
+	)
+	if rule != nil && rule.storage != "" {
+		router, ok := c.trash.(trash.Router)
+		if !ok {
+			return nil, ErrRoutingUnsupported
+		}
+		plan, err = router.PlanPutTo(path, storageType(rule.storage))
+	} else {
+		planner, ok := c.trash.(trash.Planner)
+		if !ok {
+			return nil, ErrDryRunUnsupported
+		}
+		plan, err = planner.PlanPut(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to move to trash: %w", err)
+	}
+	if c.report != nil {
+		return plan, nil
+	}
+
+	var notes []string
+	if plan.CreateTrashDir {
+		notes = append(notes, "creating "+plan.TrashDir)
+	}
+	if plan.CrossDevice {
+		notes = append(notes, "copying across devices")
+	}
+	if rule != nil {
+		notes = append(notes, "by "+rule.String())
+	}
+
+	msg := fmt.Sprintf("move %s to %s (%s trash %s)", arg, plan.TrashPath, plan.Backend, plan.TrashDir)
+	if len(notes) > 0 {
+		msg += ", " + strings.Join(notes, ", ")
+	}
+	printPlan("%s", msg)
+	return plan, nil
+}
+
+// planRestore prints what restoring file to dst would do, including how
+// a conflict with an existing file would be resolved. With --report, it
+// records the plan instead.
+func (c *CLI) planRestore(file *trash.File, dst string) error {
+	rec := restoreRecord(file, dst, outcomePlanned)
+	if _, err := os.Lstat(dst); err != nil {
+		c.plan(rec, "restore %s to %s", file.TrashPath, dst)
+		return nil
+	}
+
+	switch c.option.RestoreBy.OnConflict {
+	case "skip":
+		rec.Outcome, rec.Code = outcomeSkipped, trash.CodeFileExists
+		c.plan(rec, "skip %s, %s already exists", file.TrashPath, dst)
+	case "overwrite":
+		c.plan(rec, "move existing %s to trash", dst)
+		if c.report == nil {
+			printPlan("restore %s to %s", file.TrashPath, dst)
+		}
+	case "rename":
+		rec.Path = availablePath(dst)
+		c.plan(rec, "restore %s to %s, %s already exists", file.TrashPath, rec.Path, dst)
+	case "fail":
+		return fmt.Errorf("%s: %w", dst, trash.ErrFileExists)
+	default:
+		c.plan(rec, "ask for a new name for %s, %s already exists", file.TrashPath, dst)
+	}
+	return nil
+}
+
+// plan records rec with --report, or prints the action otherwise
+func (c *CLI) plan(rec reportRecord, format string, args ...any) {
+	if c.report != nil {
+		c.report.add(rec, nil)
+		return
+	}
+	printPlan(format, args...)
+}
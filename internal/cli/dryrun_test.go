@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func TestPut_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ft := &fakeTrash{}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.DryRun = true
+
+	if err := cli.Put([]string{file}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(ft.put) != 0 {
+		t.Errorf("dry run moved %v to trash", ft.put)
+	}
+
+	// Safety checks still apply
+	if err := cli.Put([]string{"/"}); err == nil {
+		t.Error("dry run should still refuse unsafe paths")
+	}
+}
+
+func TestPut_DryRunUnsupported(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := CLI{config: config.NewDefaultConfig(), trash: struct{ trash.Trash }{&fakeTrash{}}}
+	cli.option.DryRun = true
+	if err := cli.Put([]string{file}); !errors.Is(err, ErrDryRunUnsupported) {
+		t.Errorf("Put() error = %v, want %v", err, ErrDryRunUnsupported)
+	}
+}
+
+func TestPlanRestore(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy  string
+		dst     string
+		wantErr error
+	}{
+		{"", filepath.Join(dir, "free.txt"), nil},
+		{"", existing, nil},
+		{"skip", existing, nil},
+		{"rename", existing, nil},
+		{"overwrite", existing, nil},
+		{"fail", existing, trash.ErrFileExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+filepath.Base(tt.dst), func(t *testing.T) {
+			ft := &fakeTrash{}
+			cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+			cli.option.RestoreBy.OnConflict = tt.policy
+
+			err := cli.planRestore(&trash.File{ID: "a", Name: "a.txt", TrashPath: "/trash/files/a.txt"}, tt.dst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("planRestore() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(ft.restored) != 0 || len(ft.put) != 0 {
+				t.Errorf("dry run changed the trash: restored %v, put %v", ft.restored, ft.put)
+			}
+		})
+	}
+}
+
+func TestPrune_DryRun(t *testing.T) {
+	ft := &fakeTrash{files: []*trash.File{
+		{ID: "old", Name: "old.txt", DeletedAt: time.Now().Add(-60 * 24 * time.Hour)},
+	}}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.DryRun = true
+
+	// No prompter is set, so prompting would panic
+	if err := cli.Prune([]string{"30d"}); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(ft.removed) != 0 {
+		t.Errorf("dry run removed %v", ft.removed)
+	}
+}
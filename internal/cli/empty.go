@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/ui/table"
+	"github.com/babarot/gomi/internal/utils/fs"
+	"github.com/babarot/gomi/internal/utils/shell"
+)
+
+// ErrEmptyUnsupported is returned when the trash cannot be emptied as a whole
+var ErrEmptyUnsupported = errors.New("trash does not support --empty")
+
+// Empty permanently deletes every file in the selected trash directories.
+// The operation requires user confirmation and cannot be undone.
+func (c *CLI) Empty() error {
+	slog.Debug("emptying trash", "storage", c.option.EmptyBy.Storage, "mount", c.option.EmptyBy.Mount)
+
+	emptier, ok := c.trash.(trash.Emptier)
+	if !ok {
+		return fmt.Errorf("empty: %w", ErrEmptyUnsupported)
+	}
+
+	files, err := c.trash.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash contents: %w", err)
+	}
+
+	bins, err := selectBins(emptier.Bins(files), c.option.EmptyBy)
+	if err != nil {
+		return fmt.Errorf("empty: %w", err)
+	}
+	if len(bins) == 0 {
+		fmt.Println("Trash is already empty.")
+		return nil
+	}
+
+	total := printBins(bins)
+
+	if c.option.DryRun {
+		for _, bin := range bins {
+			printPlan("permanently delete %d files from %s", len(bin.Files)+len(bin.Leftovers), bin.Dir)
+		}
+		return nil
+	}
+
+	if !c.option.Rm.Force {
+		fmt.Println()
+		fmt.Printf("%s\n", color.New(color.FgHiRed).Sprint("WARNING: This operation is permanent and cannot be undone!"))
+		if !c.prompter.ConfirmYes(fmt.Sprintf("Do you really want to permanently delete all %d files?", total)) {
+			fmt.Println("Operation canceled.")
+			return nil
+		}
+	}
+
+	var failedDeletions []string
+	backends := make(map[trash.StorageType]bool)
+	for _, bin := range bins {
+		backends[bin.Backend] = true
+		for _, file := range bin.Files {
+			slog.Debug("removing trash file", "file", file.TrashPath)
+			if err := c.trash.Remove(file); err != nil {
+				slog.Error("failed to remove file", "file", file.TrashPath, "error", err)
+				failedDeletions = append(failedDeletions, file.TrashPath)
+			}
+		}
+		// Data without metadata and the like are not files of the trash
+		for _, path := range bin.Leftovers {
+			slog.Debug("removing trash leftover", "path", path)
+			if err := os.RemoveAll(path); err != nil {
+				slog.Error("failed to remove leftover", "path", path, "error", err)
+				failedDeletions = append(failedDeletions, path)
+			}
+		}
+	}
+
+	// Removing files one by one leaves the legacy date directories behind
+	for backend := range backends {
+		if err := emptier.Cleanup(backend); err != nil {
+			slog.Error("failed to clean up storage", "type", backend, "error", err)
+			fmt.Fprintf(os.Stderr, "failed to clean up %s storage: %v\n", backend, err)
+		}
+	}
+
+	if len(failedDeletions) > 0 {
+		fmt.Printf("Failed to remove %d files:\n", len(failedDeletions))
+		for _, file := range failedDeletions {
+			fmt.Println("-", file)
+		}
+		return fmt.Errorf("some files could not be removed")
+	}
+
+	fmt.Printf("Successfully removed %d files.\n", total)
+	return nil
+}
+
+// selectBins returns the bins matching the --storage and --mount options
+func selectBins(bins []trash.Bin, opt EmptyOption) ([]trash.Bin, error) {
+	var mount string
+	if opt.Mount != "" {
+		path, err := shell.ExpandHome(opt.Mount)
+		if err != nil {
+			return nil, err
+		}
+		if mount, err = filepath.Abs(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []trash.Bin
+	for _, bin := range bins {
+		if opt.Storage != "" && bin.Backend.String() != opt.Storage {
+			continue
+		}
+		if mount != "" && bin.Dir != mount && !isWithin(bin.Dir, mount) {
+			continue
+		}
+		selected = append(selected, bin)
+	}
+	return selected, nil
+}
+
+// printBins prints the number and size of the files in each bin, and
+// returns the total number of files. Leftovers count as files.
+func printBins(bins []trash.Bin) int {
+	var rows [][]string
+	var count int
+	var total int64
+	for _, bin := range bins {
+		var size int64
+		for _, file := range bin.Files {
+			size += newListEntry(file).Size
+		}
+		for _, path := range bin.Leftovers {
+			if usage, err := fs.DirSize(path); err == nil {
+				size += usage
+			}
+		}
+		n := len(bin.Files) + len(bin.Leftovers)
+		rows = append(rows, []string{
+			bin.Backend.String(),
+			bin.Dir,
+			strconv.Itoa(n),
+			humanize.Bytes(uint64(size)),
+		})
+		count += n
+		total += size
+	}
+
+	table.Render(os.Stdout, []string{"Backend", "Trash Directory", "Files", "Size"}, rows)
+	fmt.Println()
+	fmt.Printf("Found %d files (%s) in %d trash directories.\n", count, humanize.Bytes(uint64(total)), len(bins))
+	return count
+}
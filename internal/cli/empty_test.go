@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// fakeEmptier is a fakeTrash that groups files into fixed trash directories
+type fakeEmptier struct {
+	fakeTrash
+	bins    []trash.Bin
+	cleaned []trash.StorageType
+}
+
+func (f *fakeEmptier) Bins([]*trash.File) []trash.Bin { return f.bins }
+
+func (f *fakeEmptier) Cleanup(backend trash.StorageType) error {
+	f.cleaned = append(f.cleaned, backend)
+	return nil
+}
+
+func testBins() []trash.Bin {
+	return []trash.Bin{
+		{Backend: trash.StorageTypeXDG, Dir: "/home/u/.local/share/Trash", Files: []*trash.File{{ID: "a"}, {ID: "b"}}},
+		{Backend: trash.StorageTypeXDG, Dir: "/media/usb/.Trash-1000", Files: []*trash.File{{ID: "c"}}},
+		{Backend: trash.StorageTypeLegacy, Dir: "/home/u/.gomi", Files: []*trash.File{{ID: "d"}}},
+	}
+}
+
+func TestSelectBins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	tests := []struct {
+		name string
+		opt  EmptyOption
+		want []string
+	}{
+		{"everything", EmptyOption{}, []string{"/home/u/.local/share/Trash", "/media/usb/.Trash-1000", "/home/u/.gomi"}},
+		{"storage", EmptyOption{Storage: "legacy"}, []string{"/home/u/.gomi"}},
+		{"mount", EmptyOption{Mount: "/media/usb"}, []string{"/media/usb/.Trash-1000"}},
+		{"mount and storage", EmptyOption{Storage: "legacy", Mount: "/media/usb"}, nil},
+		{"mount is not a name prefix", EmptyOption{Mount: "/media/us"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bins, err := selectBins(testBins(), tt.opt)
+			if err != nil {
+				t.Fatalf("selectBins() error = %v", err)
+			}
+			var got []string
+			for _, bin := range bins {
+				got = append(got, bin.Dir)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectBins() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	tests := []struct {
+		name        string
+		opt         EmptyOption
+		answer      bool
+		dryRun      bool
+		wantRemoved []string
+		wantCleaned []trash.StorageType
+	}{
+		{"confirmed", EmptyOption{}, true, false, []string{"a", "b", "c", "d"}, []trash.StorageType{trash.StorageTypeXDG, trash.StorageTypeLegacy}},
+		{"canceled", EmptyOption{}, false, false, nil, nil},
+		{"dry run", EmptyOption{}, true, true, nil, nil},
+		{"xdg only", EmptyOption{Storage: "xdg"}, true, false, []string{"a", "b", "c"}, []trash.StorageType{trash.StorageTypeXDG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fe := &fakeEmptier{bins: testBins()}
+			p := &fakePrompter{answer: tt.answer}
+			cli := CLI{config: config.NewDefaultConfig(), trash: fe, prompter: p}
+			cli.option.EmptyBy = tt.opt
+			cli.option.DryRun = tt.dryRun
+
+			if err := cli.Empty(); err != nil {
+				t.Fatalf("Empty() error = %v", err)
+			}
+			if !slices.Equal(fe.removed, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", fe.removed, tt.wantRemoved)
+			}
+			slices.Sort(fe.cleaned)
+			if !slices.Equal(fe.cleaned, tt.wantCleaned) {
+				t.Errorf("cleaned = %v, want %v", fe.cleaned, tt.wantCleaned)
+			}
+			if tt.dryRun && len(p.prompts) != 0 {
+				t.Errorf("dry run should not prompt, got %v", p.prompts)
+			}
+		})
+	}
+}
+
+func TestEmpty_Leftovers(t *testing.T) {
+	dir := t.TempDir()
+	orphan := filepath.Join(dir, "files", "orphan")
+	if err := os.MkdirAll(orphan, 0700); err != nil {
+		t.Fatal(err)
+	}
+	fe := &fakeEmptier{bins: []trash.Bin{{Backend: trash.StorageTypeXDG, Dir: dir, Leftovers: []string{orphan}}}}
+	cli := CLI{config: config.NewDefaultConfig(), trash: fe, prompter: &fakePrompter{}}
+	cli.option.Rm.Force = true
+
+	if err := cli.Empty(); err != nil {
+		t.Fatalf("Empty() error = %v", err)
+	}
+	if _, err := os.Lstat(orphan); !os.IsNotExist(err) {
+		t.Errorf("leftover %s should be deleted", orphan)
+	}
+}
+
+func TestEmpty_Unsupported(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}}
+	if err := cli.Empty(); !errors.Is(err, ErrEmptyUnsupported) {
+		t.Errorf("Empty() error = %v, want %v", err, ErrEmptyUnsupported)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+)
+
+// maxNameLength bounds a single name read by --files-from
+const maxNameLength = 1 << 20
+
+// ErrPromptFromStdin is returned when rm prompts would read the answers
+// from the list of files
+var ErrPromptFromStdin = errors.New("cannot prompt while reading file names from stdin")
+
+// fileArgs returns the files to trash: args, followed by the names read
+// from --files-from. The names are read as they are consumed, so that
+// long lists are never held in memory. closeList must be called when done.
+func (c *CLI) fileArgs(args []string) (files iter.Seq2[string, error], closeList func() error, err error) {
+	closeList = func() error { return nil }
+	if c.option.FilesFrom == "" {
+		return seqOf(args), closeList, nil
+	}
+
+	var r io.Reader = os.Stdin
+	if c.option.FilesFrom != "-" {
+		f, err := os.Open(c.option.FilesFrom)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open file list: %w", err)
+		}
+		r, closeList = f, f.Close
+	}
+
+	names := readNames(r, c.option.Null)
+	return func(yield func(string, error) bool) {
+		for _, seq := range []iter.Seq2[string, error]{seqOf(args), names} {
+			for name, err := range seq {
+				if !yield(name, err) {
+					return
+				}
+			}
+		}
+	}, closeList, nil
+}
+
+// seqOf yields names without errors
+func seqOf(names []string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for _, name := range names {
+			if !yield(name, nil) {
+				return
+			}
+		}
+	}
+}
+
+// collectNames reads all of files, for checks that need the whole batch
+// before anything is moved
+func collectNames(files iter.Seq2[string, error]) ([]string, error) {
+	var names []string
+	for name, err := range files {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// readNames reads names separated by newlines or, with null, by NUL
+// bytes. Empty names are skipped.
+func readNames(r io.Reader, null bool) iter.Seq2[string, error] {
+	sep := byte('\n')
+	if null {
+		sep = 0
+	}
+
+	return func(yield func(string, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNameLength)
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			if i := bytes.IndexByte(data, sep); i >= 0 {
+				return i + 1, data[:i], nil
+			}
+			if atEOF && len(data) > 0 {
+				return len(data), data, nil
+			}
+			return 0, nil, nil
+		})
+
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				continue
+			}
+			if !yield(scanner.Text(), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield("", fmt.Errorf("failed to read file list: %w", err))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/babarot/gomi/internal/config"
+)
+
+func TestReadNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		null  bool
+		want  []string
+	}{
+		{"lines", "a\nb c\n", false, []string{"a", "b c"}},
+		{"no trailing newline", "a\nb", false, []string{"a", "b"}},
+		{"empty lines skipped", "a\n\n\nb\n", false, []string{"a", "b"}},
+		{"nul separated", "a\x00new\nline\x00", true, []string{"a", "new\nline"}},
+		{"empty", "", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for name, err := range readNames(strings.NewReader(tt.input), tt.null) {
+				if err != nil {
+					t.Fatalf("readNames() error = %v", err)
+				}
+				got = append(got, name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("readNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadNames_TooLong(t *testing.T) {
+	input := strings.Repeat("x", maxNameLength+1)
+	var err error
+	for _, e := range readNames(strings.NewReader(input), false) {
+		err = e
+	}
+	if err == nil {
+		t.Error("readNames() should fail for a name longer than the limit")
+	}
+}
+
+func TestPut_FilesFrom(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	dir := t.TempDir()
+	var list []string
+	for _, name := range []string{"a b.orig", "new\nline.orig", "-dash"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		list = append(list, path)
+	}
+	listFile := filepath.Join(dir, "list")
+	if err := os.WriteFile(listFile, []byte(strings.Join(list[:2], "\x00")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ft := &fakeTrash{}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.FilesFrom = listFile
+	cli.option.Null = true
+
+	if err := cli.Put(list[2:]); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	slices.Sort(ft.put)
+	slices.Sort(list)
+	if !slices.Equal(ft.put, list) {
+		t.Errorf("put %q, want %q", ft.put, list)
+	}
+}
+
+func TestPut_FilesFromMissing(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}}
+	cli.option.FilesFrom = filepath.Join(t.TempDir(), "none")
+	if err := cli.Put(nil); err == nil {
+		t.Error("Put() should fail for a missing file list")
+	}
+}
+
+func TestPut_FilesFromStdinStrictPrompt(t *testing.T) {
+	cli, ft, p := newStrictCLI(RmOption{Interactive: true}, true)
+	cli.option.FilesFrom = "-"
+	if err := cli.Put(nil); !errors.Is(err, ErrPromptFromStdin) {
+		t.Errorf("Put() error = %v, want %v", err, ErrPromptFromStdin)
+	}
+	if len(ft.put) != 0 || len(p.prompts) != 0 {
+		t.Errorf("put %v and prompted %v, want neither", ft.put, p.prompts)
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/utils/shell"
+)
+
+// defaultHookTimeout is used for hooks without a timeout
+const defaultHookTimeout = 30 * time.Second
+
+// hookRunner runs the core.hooks commands for the events of trash.Manager
+type hookRunner struct {
+	runID    string
+	hooks    map[string][]hook
+	progress *putProgress
+}
+
+// hook is a compiled core.hooks entry
+type hook struct {
+	command string
+	scope   []protectRule
+	timeout time.Duration
+}
+
+// newHookRunner compiles the hooks of cfg. It returns nil if there are
+// none, so that trash.Manager does not plan files for pre_put.
+func newHookRunner(cfg config.HooksConfig, runID string, progress *putProgress) *hookRunner {
+	r := &hookRunner{runID: runID, hooks: make(map[string][]hook), progress: progress}
+	for event, entries := range map[string][]config.Hook{
+		trash.EventPrePut:      cfg.PrePut,
+		trash.EventPostPut:     cfg.PostPut,
+		trash.EventPreRestore:  cfg.PreRestore,
+		trash.EventPostRestore: cfg.PostRestore,
+		trash.EventPostRemove:  cfg.PostRemove,
+	} {
+		for _, e := range entries {
+			h := hook{command: e.Command, timeout: defaultHookTimeout}
+			if d, err := time.ParseDuration(e.Timeout); err == nil && d > 0 {
+				h.timeout = d
+			}
+			for _, path := range e.Paths {
+				rule, err := newPathRule(path)
+				if err != nil {
+					slog.Warn("invalid hook path", "event", event, "path", path, "error", err)
+					continue
+				}
+				h.scope = append(h.scope, rule)
+			}
+			r.hooks[event] = append(r.hooks[event], h)
+		}
+	}
+	if len(r.hooks) == 0 {
+		return nil
+	}
+	return r
+}
+
+// hookFunc returns the callback for trash.Config.Hook, or nil
+func (r *hookRunner) hookFunc() func(trash.Event) error {
+	if r == nil {
+		return nil
+	}
+	return r.run
+}
+
+// run runs the hooks of ev in order. A failing pre_* hook stops there
+// and vetoes the operation; failing post hooks are reported and the
+// others still run.
+func (r *hookRunner) run(ev trash.Event) error {
+	var errs []error
+	for _, h := range r.hooks[ev.Name] {
+		if !h.matches(ev.File.OriginalPath) {
+			continue
+		}
+		err := h.run(ev, r.env(ev))
+		if err == nil {
+			continue
+		}
+		if ev.IsPre() {
+			return fmt.Errorf("%w: %w", trash.ErrVetoed, err)
+		}
+		r.progress.hold(func() {
+			fmt.Fprintf(os.Stderr, "warning: hook failed: %v\n", err)
+		})
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// env returns the environment variables describing ev
+func (r *hookRunner) env(ev trash.Event) []string {
+	// Where the file goes is unknown for pre_put if it cannot be planned
+	var backend string
+	if ev.File.TrashPath != "" {
+		backend = ev.File.Backend.String()
+	}
+	env := []string{
+		"GOMI_HOOK=" + ev.Name,
+		"GOMI_RUN_ID=" + r.runID,
+		"GOMI_ORIGINAL_PATH=" + ev.File.OriginalPath,
+		"GOMI_TRASH_PATH=" + ev.File.TrashPath,
+		"GOMI_BACKEND=" + backend,
+	}
+	if ev.Dst != "" {
+		env = append(env, "GOMI_RESTORE_PATH="+ev.Dst)
+	}
+	return env
+}
+
+// matches reports whether the hook applies to path
+func (h hook) matches(path string) bool {
+	if len(h.scope) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(h.scope, func(rule protectRule) bool {
+		return rule.match(path)
+	})
+}
+
+// run runs the command of the hook for ev
+func (h hook) run(ev trash.Event, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	slog.Debug("running hook", "event", ev.Name, "command", h.command, "path", ev.File.OriginalPath)
+	output, code, err := shell.RunCommandContext(ctx, h.command, env)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%s %q timed out after %v", ev.Name, h.command, h.timeout)
+	case err != nil:
+		return fmt.Errorf("%s %q: %w", ev.Name, h.command, err)
+	case code != 0:
+		msg := fmt.Sprintf("%s %q exited with status %d", ev.Name, h.command, code)
+		if output = strings.TrimSpace(output); output != "" {
+			msg += ": " + output
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
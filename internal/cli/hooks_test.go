@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func TestNewHookRunner_NoHooks(t *testing.T) {
+	if r := newHookRunner(config.HooksConfig{}, "run", nil); r.hookFunc() != nil {
+		t.Error("hookFunc() should be nil without hooks")
+	}
+}
+
+func TestHookRunner_Env(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	out := filepath.Join(t.TempDir(), "env")
+	r := newHookRunner(config.HooksConfig{
+		PostRestore: []config.Hook{{Command: `env | grep ^GOMI_ | sort > ` + out}},
+	}, "run1", nil)
+
+	err := r.run(trash.Event{
+		Name: trash.EventPostRestore,
+		File: &trash.File{OriginalPath: "/home/a.txt", TrashPath: "/trash/files/a.txt", Backend: trash.StorageTypeLegacy},
+		Dst:  "/tmp/a.txt",
+	})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"GOMI_BACKEND=legacy",
+		"GOMI_HOOK=post_restore",
+		"GOMI_ORIGINAL_PATH=/home/a.txt",
+		"GOMI_RESTORE_PATH=/tmp/a.txt",
+		"GOMI_RUN_ID=run1",
+		"GOMI_TRASH_PATH=/trash/files/a.txt",
+	}, "\n") + "\n"
+	if string(got) != want {
+		t.Errorf("environment =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHookRunner_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name       string
+		event      string
+		hook       config.Hook
+		path       string
+		wantErr    bool
+		wantVetoed bool
+		wantMsg    string
+	}{
+		{"success", trash.EventPrePut, config.Hook{Command: "true"}, "/a", false, false, ""},
+		{"veto", trash.EventPrePut, config.Hook{Command: "echo dirty >&2; exit 3"}, "/a", true, true, "exited with status 3: dirty"},
+		{"timeout", trash.EventPreRestore, config.Hook{Command: "sleep 5", Timeout: "50ms"}, "/a", true, true, "timed out after 50ms"},
+		{"in scope", trash.EventPrePut, config.Hook{Command: "false", Paths: []string{"~/src/*"}}, filepath.Join(home, "src/app/main.go"), true, true, ""},
+		{"out of scope", trash.EventPrePut, config.Hook{Command: "false", Paths: []string{"~/src/*"}}, filepath.Join(home, "docs/a.txt"), false, false, ""},
+		{"failing post hook", trash.EventPostRemove, config.Hook{Command: "false"}, "/a", true, false, "exited with status 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config.HooksConfig
+			switch tt.event {
+			case trash.EventPrePut:
+				cfg.PrePut = []config.Hook{tt.hook}
+			case trash.EventPreRestore:
+				cfg.PreRestore = []config.Hook{tt.hook}
+			case trash.EventPostRemove:
+				cfg.PostRemove = []config.Hook{tt.hook}
+			}
+			r := newHookRunner(cfg, "run", nil)
+
+			err := r.run(trash.Event{Name: tt.event, File: &trash.File{OriginalPath: tt.path}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, trash.ErrVetoed) != tt.wantVetoed {
+				t.Errorf("run() error = %v, vetoed = %v", err, tt.wantVetoed)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("run() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/dustin/go-humanize"
+	"github.com/gobwas/glob"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/ui/table"
+	"github.com/babarot/gomi/internal/utils/duration"
+	"github.com/babarot/gomi/internal/utils/fs"
+)
+
+// listEntry is a trashed file as printed by --list
+type listEntry struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	OriginalPath string    `json:"original_path"`
+	TrashPath    string    `json:"trash_path"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	Size         int64     `json:"size"`
+	IsDir        bool      `json:"is_dir"`
+	Backend      string    `json:"backend"`
+	RunID        string    `json:"run_id"`
+
+	// UnknownOrigin marks an original path that is only a placeholder
+	UnknownOrigin bool `json:"unknown_origin"`
+
+	// Recorded when the file was trashed, unknown for files trashed by
+	// other tools or older versions
+	Mode       string    `json:"mode"`
+	UID        *int      `json:"uid"`
+	GID        *int      `json:"gid"`
+	ModTime    time.Time `json:"mtime,omitzero"`
+	AccessTime time.Time `json:"atime,omitzero"`
+	Xattrs     bool      `json:"xattrs"`
+	Hostname   string    `json:"hostname"`
+	Cwd        string    `json:"cwd"`
+	Command    string    `json:"command"`
+}
+
+var listHeader = []string{
+	"id", "name", "original_path", "trash_path", "deleted_at", "size", "is_dir", "backend", "run_id", "unknown_origin",
+	"mode", "uid", "gid", "mtime", "atime", "xattrs", "hostname", "cwd", "command",
+}
+
+func newListEntry(f *trash.File) listEntry {
+	size := f.Size
+	if size == 0 && f.IsDir {
+		// Directory sizes may be unknown to the storage
+		if total, err := fs.DirSize(f.TrashPath); err == nil {
+			size = total
+		}
+	}
+	e := listEntry{
+		ID:           f.ID,
+		Name:         f.Name,
+		OriginalPath: f.GetOriginalPath(),
+		TrashPath:    f.TrashPath,
+		DeletedAt:    f.DeletedAt,
+		Size:         size,
+		IsDir:        f.IsDir,
+		Backend:      f.Backend.String(),
+		RunID:        f.RunID,
+
+		UnknownOrigin: f.UnknownOrigin,
+	}
+	if m := f.Metadata; m != nil {
+		e.Mode = m.Perm()
+		if m.UID >= 0 && m.GID >= 0 {
+			e.UID, e.GID = &m.UID, &m.GID
+		}
+		e.ModTime, e.AccessTime = m.ModTime, m.AccessTime
+		e.Xattrs = m.Xattrs
+		e.Hostname, e.Cwd, e.Command = m.Hostname, m.Cwd, m.Command
+	}
+	return e
+}
+
+func (e listEntry) record() []string {
+	return []string{
+		e.ID,
+		e.Name,
+		e.OriginalPath,
+		e.TrashPath,
+		e.DeletedAt.Format(time.RFC3339),
+		strconv.FormatInt(e.Size, 10),
+		strconv.FormatBool(e.IsDir),
+		e.Backend,
+		e.RunID,
+		strconv.FormatBool(e.UnknownOrigin),
+		e.Mode,
+		formatID(e.UID),
+		formatID(e.GID),
+		formatTime(e.ModTime),
+		formatTime(e.AccessTime),
+		strconv.FormatBool(e.Xattrs),
+		e.Hostname,
+		e.Cwd,
+		e.Command,
+	}
+}
+
+// owner returns "uid:gid" as shown in the table, or "-" if unknown
+func (e listEntry) owner() string {
+	if e.UID == nil || e.GID == nil {
+		return "-"
+	}
+	return formatID(e.UID) + ":" + formatID(e.GID)
+}
+
+// formatID returns a user or group ID, or "" if unknown
+func formatID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+// formatTime returns t in RFC 3339, or "" if unknown
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// listFilter selects the entries printed by --list
+type listFilter struct {
+	name    glob.Glob
+	within  time.Duration
+	minSize int64
+	maxSize int64
+}
+
+func newListFilter(opt OutputOption) (*listFilter, error) {
+	f := &listFilter{minSize: -1, maxSize: -1}
+	if opt.Name != "" {
+		g, err := glob.Compile(opt.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", opt.Name, err)
+		}
+		f.name = g
+	}
+	if opt.Within != "" {
+		d, err := duration.Parse(opt.Within)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", opt.Within, err)
+		}
+		f.within = d
+	}
+	if opt.MinSize != "" {
+		size, err := units.FromHumanSize(opt.MinSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", opt.MinSize, err)
+		}
+		f.minSize = size
+	}
+	if opt.MaxSize != "" {
+		size, err := units.FromHumanSize(opt.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", opt.MaxSize, err)
+		}
+		f.maxSize = size
+	}
+	return f, nil
+}
+
+func (f *listFilter) match(e listEntry) bool {
+	if f.name != nil && !f.name.Match(e.Name) {
+		return false
+	}
+	if f.within > 0 && time.Since(e.DeletedAt) > f.within {
+		return false
+	}
+	if f.minSize >= 0 && e.Size < f.minSize {
+		return false
+	}
+	if f.maxSize >= 0 && e.Size > f.maxSize {
+		return false
+	}
+	return true
+}
+
+// sortListEntries sorts entries the way ls does: dates newest first,
+// sizes largest first, and names and paths alphabetically
+func sortListEntries(entries []listEntry, key string, reverse bool) {
+	slices.SortStableFunc(entries, func(a, b listEntry) int {
+		var c int
+		switch key {
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "path":
+			c = strings.Compare(a.OriginalPath, b.OriginalPath)
+		case "size":
+			c = cmp.Compare(b.Size, a.Size)
+		default: // date
+			c = b.DeletedAt.Compare(a.DeletedAt)
+		}
+		if reverse {
+			c = -c
+		}
+		return c
+	})
+}
+
+// List prints trashed files non-interactively. It is the scriptable
+// counterpart of the restore UI (-b).
+func (c *CLI) List() error {
+	slog.Debug("listing trash contents", "format", c.option.Output.Format)
+
+	filter, err := newListFilter(c.option.Output)
+	if err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	files, err := c.trash.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash contents: %w", err)
+	}
+
+	entries := make([]listEntry, 0, len(files))
+	for _, f := range files {
+		e := newListEntry(f)
+		if filter.match(e) {
+			entries = append(entries, e)
+		}
+	}
+	sortListEntries(entries, c.option.Output.Sort, c.option.Output.Reverse)
+
+	return writeList(os.Stdout, entries, c.option.Output.Format)
+}
+
+// writeList writes entries to w in the given format
+func writeList(w io.Writer, entries []listEntry, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if format == "tsv" {
+			cw.Comma = '\t'
+		}
+		if err := cw.Write(listHeader); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := cw.Write(e.record()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default: // table
+		if len(entries) == 0 {
+			fmt.Fprintln(w, "No files in trash.")
+			return nil
+		}
+		var rows [][]string
+		for _, e := range entries {
+			size := humanize.Bytes(uint64(e.Size))
+			if e.IsDir {
+				size += " (dir)"
+			}
+			origPath := e.OriginalPath
+			if e.UnknownOrigin {
+				origPath += " (unknown origin)"
+			}
+			rows = append(rows, []string{
+				e.DeletedAt.Format(table.TimeFormat),
+				cmp.Or(e.Mode, "-"),
+				e.owner(),
+				e.Name,
+				size,
+				origPath,
+				e.Backend,
+			})
+		}
+		table.Render(w, []string{"Deleted At", "Mode", "Owner", "Name", "Size", "Original Path", "Backend"}, rows)
+		return nil
+	}
+}
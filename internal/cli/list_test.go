@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func testListEntries() []listEntry {
+	now := time.Now()
+	return []listEntry{
+		{ID: "b.txt", Name: "b.txt", OriginalPath: "/home/u/b.txt", TrashPath: "/t/b.txt", DeletedAt: now.Add(-48 * time.Hour), Size: 10, Backend: "xdg"},
+		{ID: "a.iso", Name: "a.iso", OriginalPath: "/home/u/a.iso", TrashPath: "/t/a.iso", DeletedAt: now.Add(-time.Hour), Size: 3000, Backend: "xdg"},
+		{ID: "c, d.1", Name: "c, d", OriginalPath: "/home/u/c, d", TrashPath: "/t/c, d", DeletedAt: now.Add(-24 * time.Hour), Size: 500, IsDir: true, Backend: "legacy"},
+	}
+}
+
+func TestSortListEntries(t *testing.T) {
+	tests := []struct {
+		key     string
+		reverse bool
+		want    []string
+	}{
+		{"date", false, []string{"a.iso", "c, d", "b.txt"}},
+		{"date", true, []string{"b.txt", "c, d", "a.iso"}},
+		{"name", false, []string{"a.iso", "b.txt", "c, d"}},
+		{"path", true, []string{"c, d", "b.txt", "a.iso"}},
+		{"size", false, []string{"a.iso", "c, d", "b.txt"}},
+		{"", false, []string{"a.iso", "c, d", "b.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			entries := testListEntries()
+			sortListEntries(entries, tt.key, tt.reverse)
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("sortListEntries(%q, %v) = %v, want %v", tt.key, tt.reverse, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  OutputOption
+		want int
+	}{
+		{"no filter", OutputOption{}, 3},
+		{"name glob", OutputOption{Name: "*.iso"}, 1},
+		{"within", OutputOption{Within: "1d"}, 1},
+		{"min size", OutputOption{MinSize: "500B"}, 2},
+		{"max size", OutputOption{MaxSize: "500B"}, 2},
+		{"size range", OutputOption{MinSize: "100B", MaxSize: "1KB"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newListFilter(tt.opt)
+			if err != nil {
+				t.Fatalf("newListFilter() error = %v", err)
+			}
+			var got int
+			for _, e := range testListEntries() {
+				if f.match(e) {
+					got++
+				}
+			}
+			if got != tt.want {
+				t.Errorf("matched %d entries, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilter_Invalid(t *testing.T) {
+	for _, opt := range []OutputOption{
+		{Name: "[abc"},
+		{Within: "soon"},
+		{MinSize: "big"},
+		{MaxSize: "-"},
+	} {
+		if _, err := newListFilter(opt); err == nil {
+			t.Errorf("newListFilter(%+v) should return error", opt)
+		}
+	}
+}
+
+func TestWriteList(t *testing.T) {
+	entries := testListEntries()
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeList(&buf, entries, "json"); err != nil {
+			t.Fatal(err)
+		}
+		var got []listEntry
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("invalid json: %v", err)
+		}
+		if len(got) != 3 || got[2].Backend != "legacy" || !got[2].IsDir {
+			t.Errorf("decoded %+v", got)
+		}
+	})
+
+	t.Run("json empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeList(&buf, []listEntry{}, "json"); err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(buf.String()) != "[]" {
+			t.Errorf("empty json = %q, want []", buf.String())
+		}
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeList(&buf, entries, "jsonl"); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("got %d lines, want 3", len(lines))
+		}
+		var e listEntry
+		if err := json.Unmarshal([]byte(lines[0]), &e); err != nil || e.Name != "b.txt" {
+			t.Errorf("first line = %q, %v", lines[0], err)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeList(&buf, entries, "csv"); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if lines[0] != strings.Join(listHeader, ",") {
+			t.Errorf("header = %q", lines[0])
+		}
+		if !strings.HasPrefix(lines[3], `"c, d.1","c, d","/home/u/c, d"`) {
+			t.Errorf("fields with commas should be quoted: %q", lines[3])
+		}
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeList(&buf, entries, "tsv"); err != nil {
+			t.Fatal(err)
+		}
+		first := strings.Split(buf.String(), "\n")[1]
+		if fields := strings.Split(first, "\t"); len(fields) != len(listHeader) {
+			t.Errorf("got %d fields, want %d: %q", len(fields), len(listHeader), first)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeList(&buf, entries, "table"); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "/home/u/a.iso") {
+			t.Errorf("table should contain original paths: %q", buf.String())
+		}
+	})
+}
+
+func TestNewListEntry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+	dir := t.TempDir()
+	f := &trash.File{
+		Name:         "dir",
+		OriginalPath: "Documents/dir",
+		MountRoot:    "/media/usb",
+		TrashPath:    dir,
+		IsDir:        true,
+		Backend:      trash.StorageTypeXDG,
+	}
+	e := newListEntry(f)
+	if e.OriginalPath != "/media/usb/Documents/dir" {
+		t.Errorf("OriginalPath = %q, want absolute path", e.OriginalPath)
+	}
+	if e.Backend != "xdg" {
+		t.Errorf("Backend = %q, want xdg", e.Backend)
+	}
+}
+
+func TestNewListEntry_Metadata(t *testing.T) {
+	mtime := time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)
+	f := &trash.File{
+		Name:         "a.sh",
+		OriginalPath: "/home/u/a.sh",
+		Size:         1,
+		Metadata: &trash.Metadata{
+			Mode:     0755,
+			UID:      1000,
+			GID:      100,
+			ModTime:  mtime,
+			Hostname: "host",
+			Cwd:      "/home/u",
+			Command:  "gomi a.sh",
+		},
+	}
+
+	e := newListEntry(f)
+	if e.Mode != "0755" || e.owner() != "1000:100" || !e.ModTime.Equal(mtime) || e.Command != "gomi a.sh" {
+		t.Errorf("newListEntry() = %+v", e)
+	}
+	record := e.record()
+	if len(record) != len(listHeader) {
+		t.Fatalf("got %d fields, want %d", len(record), len(listHeader))
+	}
+	if got := record[slices.Index(listHeader, "mtime")]; got != "2024-06-15T10:30:00Z" {
+		t.Errorf("mtime = %q", got)
+	}
+	if got := record[slices.Index(listHeader, "atime")]; got != "" {
+		t.Errorf("unknown atime = %q, want empty", got)
+	}
+
+	if got := record[slices.Index(listHeader, "unknown_origin")]; got != "false" {
+		t.Errorf("unknown_origin = %q, want false", got)
+	}
+
+	// Files without metadata have unknown owners
+	if e := newListEntry(&trash.File{Name: "b"}); e.UID != nil || e.owner() != "-" {
+		t.Errorf("newListEntry() = %+v, want unknown owner", e)
+	}
+}
+
+func TestWriteList_UnknownOrigin(t *testing.T) {
+	f := &trash.File{Name: "lost.txt", OriginalPath: "/home/u/lost.txt", Size: 1, UnknownOrigin: true}
+	e := newListEntry(f)
+	if !e.UnknownOrigin {
+		t.Fatal("newListEntry() should carry the unknown origin marker")
+	}
+
+	var buf bytes.Buffer
+	if err := writeList(&buf, []listEntry{e}, "table"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "/home/u/lost.txt (unknown origin)") {
+		t.Errorf("table should mark the placeholder path: %q", buf.String())
+	}
+}
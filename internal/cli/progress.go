@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-isatty"
+
+	"github.com/babarot/gomi/internal/config"
+)
+
+const (
+	// progressDelay keeps the bar hidden for batches that finish quickly
+	progressDelay = 500 * time.Millisecond
+	// progressInterval is how often the bar is redrawn
+	progressInterval = 100 * time.Millisecond
+	// progressWidth is the width of the bar itself
+	progressWidth = 20
+	// progressPathWidth is the maximum width of the current file
+	progressPathWidth = 40
+)
+
+// putProgress draws the progress of Put on a single terminal line: the
+// number of files done, the bytes moved, and the file being moved. Bytes
+// copied across devices are counted while the copy is in flight.
+// All methods do nothing on a nil *putProgress.
+type putProgress struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	total   int // 0 if unknown, e.g. with --files-from
+	done    int
+	bytes   int64
+	copying map[string]int64
+	current string
+	started time.Time
+	drawn   bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newPutProgress returns a progress bar written to w
+func newPutProgress(w io.Writer) *putProgress {
+	return &putProgress{w: w, copying: make(map[string]int64)}
+}
+
+// showProgress reports whether Put should draw a progress bar: only on a
+// terminal, and not when other output is printed for every file
+func showProgress(opt *Option, cfg *config.Config) bool {
+	if cfg.Core.Put.Progress == "never" || opt.DryRun || opt.Rm.Verbose {
+		return false
+	}
+	fd := os.Stderr.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// onCopy returns the callback for trash.Config.OnCopy, or nil
+func (p *putProgress) onCopy() func(src string, copied int64) {
+	if p == nil {
+		return nil
+	}
+	return p.copied
+}
+
+// begin starts redrawing the bar for total files (0 if unknown)
+func (p *putProgress) begin(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.total = total
+	p.started = time.Now()
+	p.stop = make(chan struct{})
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				if time.Since(p.started) >= progressDelay {
+					p.draw()
+				}
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// start records that path is being moved
+func (p *putProgress) start(path string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = path
+}
+
+// copied records the bytes of src copied so far
+func (p *putProgress) copied(src string, n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.copying[src] = n
+	p.current = src
+}
+
+// add records that path of size bytes was moved
+func (p *putProgress) add(path string, size int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes += max(size, p.copying[path])
+	delete(p.copying, path)
+}
+
+// finish records that a file was processed, whether it was moved or not
+func (p *putProgress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+}
+
+// end stops redrawing and clears the bar
+func (p *putProgress) end() {
+	if p == nil || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\x1b[K")
+	}
+}
+
+// hold clears the bar and keeps it hidden while f runs, e.g. to prompt
+// the user. Moving files is paused meanwhile.
+func (p *putProgress) hold(f func()) {
+	if p == nil {
+		f()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\x1b[K")
+	}
+	f()
+}
+
+// draw redraws the bar. p.mu must be held.
+func (p *putProgress) draw() {
+	fmt.Fprint(p.w, "\r\x1b[K"+p.line())
+	p.drawn = true
+}
+
+// line returns the text of the bar. p.mu must be held.
+func (p *putProgress) line() string {
+	bytes := p.bytes
+	for _, n := range p.copying {
+		bytes += n
+	}
+
+	var b strings.Builder
+	if p.total > 0 {
+		filled := min(p.done*progressWidth/p.total, progressWidth)
+		fmt.Fprintf(&b, "Trashing %d/%d files, %s [%s%s]",
+			p.done, p.total, humanize.Bytes(uint64(bytes)),
+			strings.Repeat("=", filled), strings.Repeat(" ", progressWidth-filled))
+	} else {
+		fmt.Fprintf(&b, "Trashing %d files, %s", p.done, humanize.Bytes(uint64(bytes)))
+	}
+	if p.current != "" {
+		b.WriteString(" " + truncatePath(p.current, progressPathWidth))
+	}
+	return b.String()
+}
+
+// truncatePath keeps the tail of path, which names the file, within width
+func truncatePath(path string, width int) string {
+	runes := []rune(path)
+	if len(runes) <= width {
+		return path
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
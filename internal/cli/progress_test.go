@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/babarot/gomi/internal/config"
+)
+
+func TestPutProgress_Line(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  int
+		update func(p *putProgress)
+		want   string
+	}{
+		{
+			name:  "known total",
+			total: 4,
+			update: func(p *putProgress) {
+				p.start("/tmp/a")
+				p.add("/tmp/a", 1000)
+				p.finish()
+				p.start("/tmp/b")
+			},
+			want: "Trashing 1/4 files, 1.0 kB [=====               ] /tmp/b",
+		},
+		{
+			name:  "unknown total",
+			total: 0,
+			update: func(p *putProgress) {
+				p.add("/tmp/a", 1000)
+				p.finish()
+			},
+			want: "Trashing 1 files, 1.0 kB",
+		},
+		{
+			name:  "copy in flight",
+			total: 1,
+			update: func(p *putProgress) {
+				p.start("/mnt/usb/dir")
+				p.copied("/mnt/usb/dir", 2000)
+			},
+			want: "Trashing 0/1 files, 2.0 kB [                    ] /mnt/usb/dir",
+		},
+		{
+			name:  "copied directory counts its bytes",
+			total: 1,
+			update: func(p *putProgress) {
+				p.copied("/mnt/usb/dir", 3000)
+				p.add("/mnt/usb/dir", 0)
+				p.finish()
+			},
+			want: "Trashing 1/1 files, 3.0 kB [====================] /mnt/usb/dir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPutProgress(&bytes.Buffer{})
+			p.total = tt.total
+			tt.update(p)
+			if got := p.line(); got != tt.want {
+				t.Errorf("line() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPutProgress_Nil(t *testing.T) {
+	var p *putProgress
+	if p.onCopy() != nil {
+		t.Error("onCopy() on nil progress should be nil")
+	}
+	// None of these may panic
+	p.begin(1)
+	p.start("/tmp/a")
+	p.copied("/tmp/a", 1)
+	p.add("/tmp/a", 1)
+	p.finish()
+	p.end()
+}
+
+func TestPutProgress_End(t *testing.T) {
+	var buf bytes.Buffer
+	p := newPutProgress(&buf)
+	p.begin(1)
+	p.finish()
+	p.end()
+	// A batch that finishes before progressDelay leaves no output
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want nothing", buf.String())
+	}
+
+	p.drawn = true
+	p.begin(1)
+	p.end()
+	if !strings.HasSuffix(buf.String(), "\r\x1b[K") {
+		t.Errorf("output = %q, want the line cleared", buf.String())
+	}
+}
+
+func TestTruncatePath(t *testing.T) {
+	if got := truncatePath("/tmp/a", 10); got != "/tmp/a" {
+		t.Errorf("truncatePath() = %q, want unchanged", got)
+	}
+	if got := truncatePath("/home/user/project/file.txt", 10); got != "…/file.txt" {
+		t.Errorf("truncatePath() = %q, want %q", got, "…/file.txt")
+	}
+}
+
+func TestPutWorkers(t *testing.T) {
+	tests := []struct {
+		name   string
+		flag   int
+		config int
+		want   int
+	}{
+		{"flag wins", 3, 5, 3},
+		{"config", 0, 5, 5},
+		{"number of CPUs", 0, 0, runtime.NumCPU()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Core.Put.Workers = tt.config
+			cli := CLI{config: cfg, option: Option{Workers: tt.flag}}
+			if got := cli.putWorkers(); got != tt.want {
+				t.Errorf("putWorkers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/utils/shell"
+)
+
+// protectMarker protects the directory containing it, and everything
+// under it, from being moved to trash
+const protectMarker = ".gomi-protect"
+
+// Modes of core.trash.forbidden_paths entries
+const (
+	protectRefuse  = "refuse"
+	protectConfirm = "confirm"
+)
+
+// protection tells whether a path may be moved to trash. The zero value
+// means it is not protected.
+type protection struct {
+	// mode is protectRefuse or protectConfirm
+	mode string
+
+	// reason is the forbidden_paths entry or the marker file that matched
+	reason string
+}
+
+// pathGuard matches paths against core.trash.forbidden_paths and
+// protectMarker files
+type pathGuard struct {
+	rules []protectRule
+
+	// mu serializes the confirmations of concurrent Put workers
+	mu sync.Mutex
+
+	// trees caches the walks of directories, shared by the confirm_over
+	// summary and the checks of Put workers
+	trees   map[string]treeScan
+	treesMu sync.Mutex
+}
+
+// treeScan is what a walk of a directory found under it
+type treeScan struct {
+	found protection
+
+	// measured is set if the walk also took the figures below, which
+	// core.put.confirm_over needs
+	measured bool
+	count    int   // files and directories, the directory included
+	size     int64 // bytes of regular files
+	depth    int   // depth of the deepest entry
+}
+
+// protectRule is a compiled entry of core.trash.forbidden_paths
+type protectRule struct {
+	entry string
+	mode  string
+
+	// Exactly one of these is set. paths holds the expanded path and,
+	// if it differs, the path with symlinks resolved.
+	paths []string
+	glob  glob.Glob
+	regex *regexp.Regexp
+
+	// prefix is the directory part of the glob before its first wildcard
+	prefix string
+}
+
+// newPathGuard compiles the forbidden_paths entries. Entries that cannot
+// be compiled are skipped; the config validation reports them.
+func newPathGuard(entries []config.ForbiddenPath) *pathGuard {
+	g := &pathGuard{}
+	for _, e := range entries {
+		rule := protectRule{entry: e.Path, mode: e.Mode}
+		if rule.mode == "" {
+			rule.mode = protectRefuse
+		}
+
+		if e.Regex != "" {
+			re, err := regexp.Compile(e.Regex)
+			if err != nil {
+				slog.Warn("invalid forbidden path regex", "regex", e.Regex, "error", err)
+				continue
+			}
+			rule.entry, rule.regex = "/"+e.Regex+"/", re
+			g.rules = append(g.rules, rule)
+			continue
+		}
+
+		pathRule, err := newPathRule(e.Path)
+		if err != nil {
+			slog.Warn("invalid forbidden path", "path", e.Path, "error", err)
+			continue
+		}
+		rule.paths, rule.glob, rule.prefix = pathRule.paths, pathRule.glob, pathRule.prefix
+		g.rules = append(g.rules, rule)
+	}
+	return g
+}
+
+// newPathRule compiles a path or glob pattern, after ~ and environment
+// variables are expanded
+func newPathRule(entry string) (protectRule, error) {
+	path, err := shell.ExpandHome(entry)
+	if err != nil {
+		return protectRule{}, err
+	}
+	path = filepath.Clean(path)
+
+	rule := protectRule{entry: entry}
+	if i := strings.IndexAny(path, "*?[{"); i >= 0 {
+		gl, err := glob.Compile(path, filepath.Separator)
+		if err != nil {
+			return protectRule{}, err
+		}
+		rule.glob = gl
+		rule.prefix = path[:strings.LastIndexByte(path[:i], filepath.Separator)+1]
+	} else {
+		rule.paths = []string{path}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+			rule.paths = append(rule.paths, resolved)
+		}
+	}
+	return rule, nil
+}
+
+// check returns the protection of the absolute path. Both path and the
+// path with the symlinks of its parent directories resolved are checked,
+// so that protected files cannot be reached through a link. A directory
+// is also protected by what is under it, as it is moved along with it.
+// A refusing rule or marker wins over a confirming rule.
+func (g *pathGuard) check(path string) protection {
+	candidates := []string{path}
+	if resolved := resolveParent(path); resolved != path {
+		candidates = append(candidates, resolved)
+	}
+
+	found := g.match(candidates)
+	if found.mode == protectRefuse {
+		return found
+	}
+
+	for _, c := range candidates {
+		if marker, ok := findMarker(c); ok {
+			return protection{mode: protectRefuse, reason: marker}
+		}
+	}
+
+	if fi, err := os.Lstat(path); err == nil && fi.IsDir() {
+		if p := g.scanTree(path, false).found; p.mode == protectRefuse || found.mode == "" {
+			return p
+		}
+	}
+	return found
+}
+
+// match returns the protection of the first refusing rule matching one of
+// the candidates, or else of the first confirming one
+func (g *pathGuard) match(candidates []string) protection {
+	return matchRules(g.rules, candidates)
+}
+
+// matchRules is match among rules
+func matchRules(rules []protectRule, candidates []string) protection {
+	var found protection
+	for _, rule := range rules {
+		if !slices.ContainsFunc(candidates, rule.match) {
+			continue
+		}
+		if rule.mode == protectRefuse {
+			return protection{mode: protectRefuse, reason: rule.entry}
+		}
+		if found.mode == "" {
+			found = protection{mode: rule.mode, reason: rule.entry}
+		}
+	}
+	return found
+}
+
+// scanTree walks the directory dir for the protection of the files under
+// it: a marker file or a rule matching one of them. Rules are only matched
+// in subtrees they can match something in. With measure, the walk also
+// takes the figures of core.put.confirm_over. Walks are cached, so that a
+// directory is walked once per run. Entries that cannot be read are
+// skipped.
+func (g *pathGuard) scanTree(dir string, measure bool) treeScan {
+	g.treesMu.Lock()
+	s, ok := g.trees[dir]
+	g.treesMu.Unlock()
+	if ok && (s.measured || !measure) {
+		return s
+	}
+
+	resolved := resolveParent(dir)
+	sep := string(filepath.Separator)
+	// The rules that can match under each directory walked, if any
+	rulesUnder := map[string][]protectRule{}
+	if rules := g.rulesUnder(nil, dir, resolved); len(rules) > 0 {
+		rulesUnder[dir] = rules
+	}
+
+	s = treeScan{measured: measure}
+	_ = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel := strings.TrimPrefix(p, dir+sep)
+		if measure {
+			s.count++
+			if d.Type().IsRegular() {
+				if info, err := d.Info(); err == nil {
+					s.size += info.Size()
+				}
+			}
+			if p != dir {
+				s.depth = max(s.depth, strings.Count(rel, sep)+1)
+			}
+		}
+		if p == dir {
+			return nil
+		}
+
+		if d.Name() == protectMarker {
+			s.found = protection{mode: protectRefuse, reason: p}
+			return filepath.SkipAll
+		}
+		rules := rulesUnder[filepath.Dir(p)]
+		if len(rules) == 0 {
+			return nil
+		}
+		candidates := []string{p}
+		if resolved != dir {
+			candidates = append(candidates, filepath.Join(resolved, rel))
+		}
+		m := matchRules(rules, candidates)
+		if m.mode == protectRefuse {
+			s.found = m
+			return filepath.SkipAll
+		}
+		if s.found.mode == "" {
+			s.found = m
+		}
+		if d.IsDir() {
+			if under := g.rulesUnder(rules, candidates...); len(under) > 0 {
+				rulesUnder[p] = under
+			}
+		}
+		return nil
+	})
+
+	g.treesMu.Lock()
+	if g.trees == nil {
+		g.trees = make(map[string]treeScan)
+	}
+	g.trees[dir] = s
+	g.treesMu.Unlock()
+	return s
+}
+
+// rulesUnder returns the rules, among rules or else all of them, that can
+// match something under one of dirs
+func (g *pathGuard) rulesUnder(rules []protectRule, dirs ...string) []protectRule {
+	if rules == nil {
+		rules = g.rules
+	}
+	var under []protectRule
+	for _, rule := range rules {
+		if slices.ContainsFunc(dirs, rule.under) {
+			under = append(under, rule)
+		}
+	}
+	return under
+}
+
+// under reports whether r can match something under the directory dir.
+// Regular expressions always can.
+func (r protectRule) under(dir string) bool {
+	dir += string(filepath.Separator)
+	switch {
+	case r.regex != nil:
+		return true
+	case r.glob != nil:
+		return strings.HasPrefix(r.prefix, dir) || strings.HasPrefix(dir, r.prefix)
+	default:
+		return slices.ContainsFunc(r.paths, func(protected string) bool {
+			return strings.HasPrefix(protected, dir)
+		})
+	}
+}
+
+// match reports whether path or one of its parent directories matches
+func (r protectRule) match(path string) bool {
+	switch {
+	case r.regex != nil:
+		return r.regex.MatchString(path)
+	case r.glob != nil:
+		for p := path; ; p = filepath.Dir(p) {
+			if r.glob.Match(p) {
+				return true
+			}
+			if filepath.Dir(p) == p {
+				return false
+			}
+		}
+	default:
+		for _, protected := range r.paths {
+			if path == protected {
+				return true
+			}
+			// The root directory only protects itself
+			if filepath.Dir(protected) != protected && strings.HasPrefix(path, protected+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// resolveParent returns path with the symlinks of its parent directories
+// resolved. The last element is kept, as trashing a link moves the link.
+func resolveParent(path string) string {
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return path
+	}
+	return filepath.Join(dir, filepath.Base(path))
+}
+
+// findMarker returns the protectMarker file in path or in one of its
+// parent directories
+func findMarker(path string) (string, bool) {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		marker := filepath.Join(dir, protectMarker)
+		if _, err := os.Lstat(marker); err == nil {
+			return marker, true
+		}
+		if filepath.Dir(dir) == dir {
+			return "", false
+		}
+	}
+}
+
+// forbiddenPaths returns the guard built from the config. Put builds it
+// before starting its workers, which then share it.
+func (c *CLI) forbiddenPaths() *pathGuard {
+	if c.guard == nil {
+		c.guard = newPathGuard(c.config.Core.Trash.ForbiddenPaths)
+	}
+	return c.guard
+}
+
+// checkProtected returns an error if path is protected from being moved
+// to trash. For paths to be confirmed, the user is asked unless -f is
+// given, in which case they are refused; ok is false if the user declined.
+func (c *CLI) checkProtected(arg, path string) (ok bool, err error) {
+	guard := c.forbiddenPaths()
+	p := guard.check(path)
+	switch {
+	case p.mode == "":
+		return true, nil
+	case p.mode == protectConfirm && c.option.DryRun:
+		return true, nil
+	case p.mode == protectConfirm && !c.option.Rm.Force:
+		guard.mu.Lock()
+		defer guard.mu.Unlock()
+		prompt := fmt.Sprintf("%s is protected by %s. Move it to trash anyway?", arg, p.reason)
+		var confirmed bool
+		c.progress.hold(func() { confirmed = c.prompter.Confirm(prompt) })
+		return confirmed, nil
+	}
+	return false, fmt.Errorf("%w: %q (%s)", ErrForbiddenPath, arg, p.reason)
+}
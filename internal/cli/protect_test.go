@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/babarot/gomi/internal/config"
+)
+
+func TestPathGuard_Check(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, dir := range []string{"src/app/.git", "marked/sub", "outer/inner", "secret", "docs"} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"marked", "outer/inner"} {
+		if err := os.WriteFile(filepath.Join(home, dir, protectMarker), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(home, "secret"), filepath.Join(home, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	guard := newPathGuard([]config.ForbiddenPath{
+		{Path: "~/src/*/.git"},
+		{Regex: `/\.ssh(/|$)`},
+		{Path: "$HOME/secret"},
+		{Path: "~/docs", Mode: protectConfirm},
+		{Regex: `/docs/keep$`},
+	})
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"glob", filepath.Join(home, "src/app/.git"), protectRefuse},
+		{"under glob", filepath.Join(home, "src/app/.git/config"), protectRefuse},
+		{"outside glob", filepath.Join(home, "src/app/main.go"), ""},
+		{"containing a glob match", filepath.Join(home, "src"), protectRefuse},
+		{"regex", filepath.Join(home, ".ssh/id_ed25519"), protectRefuse},
+		{"expanded path", filepath.Join(home, "secret/key"), protectRefuse},
+		{"through a symlink", filepath.Join(home, "link/key"), protectRefuse},
+		{"the symlink itself", filepath.Join(home, "link"), ""},
+		{"confirm", filepath.Join(home, "docs/a.txt"), protectConfirm},
+		{"refuse wins over confirm", filepath.Join(home, "docs/keep"), protectRefuse},
+		{"marked directory", filepath.Join(home, "marked"), protectRefuse},
+		{"under marked directory", filepath.Join(home, "marked/sub/file"), protectRefuse},
+		{"containing a marked directory", filepath.Join(home, "outer"), protectRefuse},
+		{"unprotected", filepath.Join(home, "other.txt"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guard.check(tt.path); got.mode != tt.want {
+				t.Errorf("check(%q) = %+v, want mode %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathGuard_ScanTree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	big := filepath.Join(home, "big")
+	files := map[string]int{}
+	for i := range 20 {
+		for j := range 50 {
+			files[fmt.Sprintf("d%d/f%d", i, j)] = 1
+		}
+	}
+	writeTree(t, big, files)
+
+	guard := newPathGuard([]config.ForbiddenPath{
+		{Path: "~/src/*/.git"},
+		{Path: "~/big/d3/f7", Mode: protectConfirm},
+	})
+
+	// Only the rules that can match under a directory are matched there
+	if got := guard.rulesUnder(nil, big); len(got) != 1 || got[0].entry != "~/big/d3/f7" {
+		t.Errorf("rulesUnder(%s) = %v, want the rule under it", big, got)
+	}
+	if got := guard.rulesUnder(nil, filepath.Join(big, "d4")); len(got) != 0 {
+		t.Errorf("rulesUnder(d4) = %v, want none", got)
+	}
+
+	// The big directory, 20 directories and 1000 files
+	tree := guard.scanTree(big, true)
+	if tree.count != 1021 || tree.size != 1000 || tree.depth != 2 || tree.found.mode != protectConfirm {
+		t.Errorf("scanTree() = %+v, want 1021 items, 1000 bytes, depth 2 and a confirming rule", tree)
+	}
+
+	// Put checks the directory with the walk of the confirm_over summary,
+	// so a marker created since is not seen
+	if err := os.WriteFile(filepath.Join(big, "d0", protectMarker), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := guard.check(big); got.mode != protectConfirm {
+		t.Errorf("check(%s) = %+v, want the cached walk", big, got)
+	}
+}
+
+func TestPut_MarkerUnderDirectory(t *testing.T) {
+	parent := t.TempDir()
+	child := filepath.Join(parent, "child")
+	if err := os.Mkdir(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(child, protectMarker), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ft := &fakeTrash{}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.Rm.Recursive = true
+
+	if err := cli.Put([]string{parent}); !errors.Is(err, ErrForbiddenPath) {
+		t.Errorf("Put() error = %v, want %v", err, ErrForbiddenPath)
+	}
+	if len(ft.put) > 0 {
+		t.Errorf("put %v, want nothing", ft.put)
+	}
+}
+
+func TestCheckProtected_Confirm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+
+	tests := []struct {
+		name       string
+		answer     bool
+		force      bool
+		dryRun     bool
+		wantOK     bool
+		wantErr    error
+		wantPrompt bool
+	}{
+		{"confirmed", true, false, false, true, nil, true},
+		{"declined", false, false, false, false, nil, true},
+		{"refused with -f", true, true, false, false, ErrForbiddenPath, false},
+		{"not asked with --dry-run", false, false, true, true, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Core.Trash.ForbiddenPaths = []config.ForbiddenPath{{Path: dir, Mode: protectConfirm}}
+			p := &fakePrompter{answer: tt.answer}
+			cli := CLI{config: cfg, prompter: p}
+			cli.option.Rm.Force = tt.force
+			cli.option.DryRun = tt.dryRun
+
+			ok, err := cli.checkProtected(path, path)
+			if ok != tt.wantOK || !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkProtected() = %v, %v, want %v, %v", ok, err, tt.wantOK, tt.wantErr)
+			}
+			if got := len(p.prompts) > 0; got != tt.wantPrompt {
+				t.Errorf("prompted = %v, want %v", got, tt.wantPrompt)
+			}
+		})
+	}
+}
@@ -4,15 +4,25 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/docker/go-units"
+	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
+	"github.com/gobwas/glob"
 
 	"github.com/babarot/gomi/internal/trash"
 	"github.com/babarot/gomi/internal/trash/xdg"
 	"github.com/babarot/gomi/internal/ui/table"
 	"github.com/babarot/gomi/internal/utils/duration"
+	"github.com/babarot/gomi/internal/utils/shell"
 )
 
 var (
@@ -20,7 +30,11 @@ var (
 	ErrInvalidArgument = errors.New("prune requires an argument (e.g., orphans or duration like '30d')")
 
 	// Orphans-related errors
-	ErrOrphansCombination = errors.New("orphans argument cannot be combined with other arguments")
+	ErrOrphansCombination = errors.New("orphans, orphaned-data and adopt arguments cannot be combined with other arguments")
+
+	// Selector-related errors
+	ErrInvalidSelector = errors.New("invalid selector, use size>N, size<N, name:GLOB, name~REGEXP or from:PATH")
+	ErrInvalidKeepLast = errors.New("keep-last must be a positive number")
 
 	// Duration-related errors
 	ErrInvalidDuration     = errors.New("invalid duration format")
@@ -39,75 +53,247 @@ func (c *CLI) Prune(args []string) error {
 	}
 
 	for _, arg := range args {
-		if arg == "orphans" {
-			if len(args) > 1 {
-				return fmt.Errorf("prune: %w", ErrOrphansCombination)
-			}
-			return c.removeOrphanedMetadata()
+		var run func() error
+		switch arg {
+		case "orphans":
+			run = c.removeOrphanedMetadata
+		case "orphaned-data":
+			run = c.listOrphanedData
+		case "adopt":
+			run = c.adoptOrphanedData
+		default:
+			continue
 		}
+		if len(args) > 1 {
+			return fmt.Errorf("prune: %w", ErrOrphansCombination)
+		}
+		return run()
 	}
 
-	// Parse durations
-	var durations []time.Duration
+	sel, err := parsePruneArgs(args)
+	if err != nil {
+		return fmt.Errorf("prune: %w", err)
+	}
+	return c.permanentlyDelete(sel)
+}
+
+// pruneSelector picks the files to prune. All of its criteria must match.
+type pruneSelector struct {
+	// durations are the age boundaries. For a single duration, files
+	// older than it match. For more, files whose age falls between the
+	// shortest and longest match.
+	durations []time.Duration
+
+	// criteria on the name and size of files
+	trash.SelectOptions
+
+	// from holds absolute original path prefixes
+	from []string
+
+	// keepLast keeps the newest N copies of each original path
+	keepLast int
+
+	// olderThan only matches files older than this, in addition to durations
+	olderThan time.Duration
+
+	// desc describes the criteria other than age for the summary
+	desc []string
+}
+
+// parsePruneArgs parses prune arguments: durations, "size>N", "size<N"
+// (also >= and <=), "name:GLOB", "name~REGEXP", "from:PATH",
+// "keep-last=N" and "older=DURATION"
+func parsePruneArgs(args []string) (*pruneSelector, error) {
+	sel := &pruneSelector{}
 	for _, arg := range args {
-		if arg == "" {
-			return fmt.Errorf("prune: %w", ErrInvalidArgument)
+		var err error
+		switch {
+		case arg == "":
+			return nil, ErrInvalidArgument
+		case strings.HasPrefix(arg, "size"):
+			err = sel.parseSize(strings.TrimPrefix(arg, "size"))
+		case strings.HasPrefix(arg, "name:"):
+			pattern := strings.TrimPrefix(arg, "name:")
+			if _, err = glob.Compile(pattern); err == nil {
+				sel.Globs = append(sel.Globs, pattern)
+				sel.desc = append(sel.desc, fmt.Sprintf("named %s", pattern))
+			}
+		case strings.HasPrefix(arg, "name~"):
+			pattern := strings.TrimPrefix(arg, "name~")
+			if _, err = regexp.Compile(pattern); err == nil {
+				sel.Patterns = append(sel.Patterns, pattern)
+				sel.desc = append(sel.desc, fmt.Sprintf("named like /%s/", pattern))
+			}
+		case strings.HasPrefix(arg, "from:"):
+			err = sel.parseFrom(strings.TrimPrefix(arg, "from:"))
+		case strings.HasPrefix(arg, "keep-last="):
+			sel.keepLast, err = strconv.Atoi(strings.TrimPrefix(arg, "keep-last="))
+			if err == nil && sel.keepLast <= 0 {
+				err = ErrInvalidKeepLast
+			}
+			sel.desc = append(sel.desc, fmt.Sprintf("not among the %d most recent copies of their path", sel.keepLast))
+		case strings.HasPrefix(arg, "older="):
+			sel.olderThan, err = duration.Parse(strings.TrimPrefix(arg, "older="))
+			sel.desc = append(sel.desc, fmt.Sprintf("older than %d days", int(sel.olderThan.Hours()/24)))
+		default:
+			var d time.Duration
+			d, err = duration.Parse(arg)
+			sel.durations = append(sel.durations, d)
 		}
-		duration, err := duration.Parse(arg)
 		if err != nil {
-			return fmt.Errorf("prune: %w", err)
+			return nil, fmt.Errorf("%s: %w", arg, err)
 		}
-		durations = append(durations, duration)
 	}
-	return c.permanentlyDeleteByTimeRange(durations)
+	return sel, nil
 }
 
-// permanentlyDeleteByTimeRange removes files from trash based on their age.
-// For a single duration, it removes files older than the specified duration.
-// For multiple durations, it removes files whose age falls between the shortest and longest durations.
-// The operation requires user confirmation and cannot be undone.
-func (c *CLI) permanentlyDeleteByTimeRange(durations []time.Duration) error {
-	if len(durations) == 0 {
+// parseSize parses the comparison of a "size" selector, e.g. ">1GB"
+func (p *pruneSelector) parseSize(expr string) error {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		value, ok := strings.CutPrefix(expr, op)
+		if !ok {
+			continue
+		}
+		size, err := units.FromHumanSize(value)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case ">":
+			p.MinSize = size + 1
+		case ">=":
+			p.MinSize = size
+		case "<":
+			p.MaxSize = size - 1
+		case "<=":
+			p.MaxSize = size
+		}
+		if p.MaxSize < 0 || (strings.HasPrefix(op, "<") && p.MaxSize == 0) {
+			// A zero MaxSize means no limit
+			return ErrInvalidSelector
+		}
+		p.desc = append(p.desc, fmt.Sprintf("size %s %s", op, humanize.Bytes(uint64(size))))
 		return nil
 	}
+	return ErrInvalidSelector
+}
 
-	// Find newest and oldest age boundaries
-	newestAge := durations[0]
-	oldestAge := durations[0]
-	for _, d := range durations {
-		if d < newestAge {
-			newestAge = d
+// parseFrom parses the path of a "from" selector
+func (p *pruneSelector) parseFrom(path string) error {
+	path, err := shell.ExpandHome(path)
+	if err != nil {
+		return err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	p.from = append(p.from, path)
+	p.desc = append(p.desc, fmt.Sprintf("from %s", path))
+	return nil
+}
+
+// ageRange returns the newest and oldest age boundaries
+func (p *pruneSelector) ageRange() (time.Duration, time.Duration) {
+	if len(p.durations) == 0 {
+		return 0, 0
+	}
+	return slices.Min(p.durations), slices.Max(p.durations)
+}
+
+// matchAge reports whether the file was deleted within the age boundaries
+func (p *pruneSelector) matchAge(file *trash.File) bool {
+	newestAge, oldestAge := p.ageRange()
+	age := time.Since(file.DeletedAt)
+	if p.olderThan > 0 && age <= p.olderThan {
+		return false
+	}
+	switch len(p.durations) {
+	case 0:
+		return true
+	case 1:
+		// Single duration: get files older than duration
+		return age > oldestAge
+	default:
+		// Multiple durations: get files between newest and oldest age
+		return age >= newestAge && age <= oldestAge
+	}
+}
+
+// matchFrom reports whether the file was deleted from below a "from" path
+func (p *pruneSelector) matchFrom(file *trash.File) bool {
+	if len(p.from) == 0 {
+		return true
+	}
+	path := file.GetOriginalPath()
+	for _, prefix := range p.from {
+		if path == prefix || isWithin(path, prefix) {
+			return true
 		}
-		if d > oldestAge {
-			oldestAge = d
+	}
+	return false
+}
+
+// selectFiles returns the files matching every criterion
+func (p *pruneSelector) selectFiles(files []*trash.File) []*trash.File {
+	if p.keepLast > 0 {
+		files = olderCopies(files, p.keepLast)
+	}
+	files = slices.DeleteFunc(slices.Clone(files), func(f *trash.File) bool {
+		return !p.matchAge(f) || !p.matchFrom(f)
+	})
+	// Name and size come last as sizing directories is expensive
+	return trash.Select(files, p.SelectOptions)
+}
+
+// olderCopies groups files by their original path and returns all but
+// the newest n of each group
+func olderCopies(files []*trash.File, n int) []*trash.File {
+	groups := make(map[string][]*trash.File)
+	for _, f := range files {
+		path := f.GetOriginalPath()
+		groups[path] = append(groups[path], f)
+	}
+
+	var older []*trash.File
+	for _, path := range slices.Sorted(maps.Keys(groups)) {
+		copies := groups[path]
+		if len(copies) <= n {
+			continue
 		}
+		slices.SortFunc(copies, func(a, b *trash.File) int {
+			return b.DeletedAt.Compare(a.DeletedAt)
+		})
+		older = append(older, copies[n:]...)
 	}
+	return older
+}
 
+// permanentlyDelete removes the files matching sel from trash.
+// The operation requires user confirmation and cannot be undone.
+func (c *CLI) permanentlyDelete(sel *pruneSelector) error {
 	slog.Debug("Get all files from trash")
 	files, err := c.trash.List()
 	if err != nil {
 		return fmt.Errorf("failed to list trash contents: %w", err)
 	}
 
-	// Filter files based on time range
-	var filesToDelete []*trash.File
-	for _, file := range files {
-		age := time.Since(file.DeletedAt)
-		if len(durations) == 1 {
-			// Single duration: get files older than duration
-			if age > oldestAge {
-				filesToDelete = append(filesToDelete, file)
-			}
-		} else {
-			// Multiple durations: get files between newest and oldest age
-			if age >= newestAge && age <= oldestAge {
-				filesToDelete = append(filesToDelete, file)
-			}
+	filesToDelete := sel.selectFiles(files)
+	if len(filesToDelete) == 0 {
+		if c.report == nil {
+			fmt.Println("No matching files found.")
 		}
+		return nil
 	}
 
-	if len(filesToDelete) == 0 {
-		fmt.Println("No matching files found.")
+	if c.option.DryRun {
+		if c.report == nil {
+			printDeletionSummary(filesToDelete, sel)
+		}
+		for _, file := range filesToDelete {
+			c.plan(pruneRecord(file, outcomePlanned), "permanently delete %s (deleted %s from %s)",
+				file.TrashPath, file.DeletedAt.Format(table.TimeFormat), file.GetOriginalPath())
+		}
 		return nil
 	}
 
@@ -117,7 +303,7 @@ func (c *CLI) permanentlyDeleteByTimeRange(durations []time.Duration) error {
 			Order:            table.SortDesc,
 		})
 		fmt.Println()
-		printDeletionSummary(filesToDelete, newestAge, oldestAge, len(durations) == 1)
+		printDeletionSummary(filesToDelete, sel)
 
 		// First confirmation
 		if !c.prompter.Confirm(fmt.Sprintf("Are you sure you want to remove these %d files?", len(filesToDelete))) {
@@ -137,37 +323,60 @@ func (c *CLI) permanentlyDeleteByTimeRange(durations []time.Duration) error {
 
 	// Remove files
 	var failedDeletions []string
+	var errs []error
 	for _, file := range filesToDelete {
 		slog.Debug("removing trash file", "file", file.OriginalPath)
 		if err := c.trash.Remove(file); err != nil {
 			slog.Error("failed to remove file", "file", file.Name, "error", err)
 			failedDeletions = append(failedDeletions, file.Name)
+			errs = append(errs, err)
+			c.report.add(pruneRecord(file, outcomeFailed), err)
+			continue
 		}
+		c.report.add(pruneRecord(file, outcomeDeleted), nil)
 	}
 
 	if len(failedDeletions) > 0 {
-		fmt.Printf("Failed to remove %d files:\n", len(failedDeletions))
-		for _, file := range failedDeletions {
-			fmt.Println("-", file)
+		if c.report == nil {
+			fmt.Printf("Failed to remove %d files:\n", len(failedDeletions))
+			for _, file := range failedDeletions {
+				fmt.Println("-", file)
+			}
 		}
-		return fmt.Errorf("some files could not be removed")
+		return fmt.Errorf("some files could not be removed: %w", newBatchError(errs))
 	}
 
-	fmt.Printf("Successfully removed %d files.\n", len(filesToDelete))
+	if c.report == nil {
+		fmt.Printf("Successfully removed %d files.\n", len(filesToDelete))
+	}
 	return nil
 }
 
 // printDeletionSummary prints a summary of the files to be deleted
-func printDeletionSummary(files []*trash.File, newestAge, oldestAge time.Duration, isSingleDuration bool) {
-	if isSingleDuration {
+func printDeletionSummary(files []*trash.File, sel *pruneSelector) {
+	var total int64
+	for _, file := range files {
+		total += newListEntry(file).Size
+	}
+
+	criteria := slices.Clone(sel.desc)
+	newestAge, oldestAge := sel.ageRange()
+	switch len(sel.durations) {
+	case 0:
+	case 1:
 		days := int(oldestAge.Hours() / 24)
-		fmt.Printf("Found %d files that are older than %d days.\n", len(files), days)
-	} else {
+		criteria = append([]string{fmt.Sprintf("older than %d days", days)}, criteria...)
+	default:
 		minDays := int(newestAge.Hours() / 24)
 		maxDays := int(oldestAge.Hours() / 24)
-		fmt.Printf("Found %d files that were moved to trash between %d and %d days ago.\n",
-			len(files), minDays, maxDays)
+		criteria = append([]string{fmt.Sprintf("moved to trash between %d and %d days ago", minDays, maxDays)}, criteria...)
+	}
+
+	fmt.Printf("Found %d files (%s)", len(files), humanize.Bytes(uint64(total)))
+	if len(criteria) > 0 {
+		fmt.Printf(" that are %s", strings.Join(criteria, ", "))
 	}
+	fmt.Println(".")
 }
 
 // removeOrphanedMetadata removes .trashinfo files that have lost their corresponding data files.
@@ -200,6 +409,13 @@ func (c *CLI) removeOrphanedMetadata() error {
 		return nil
 	}
 
+	if c.option.DryRun {
+		for _, file := range orphanedFiles {
+			printPlan("remove orphaned metadata %s", file.TrashInfoPath)
+		}
+		return nil
+	}
+
 	// Confirm deletion unless forced
 	if !c.option.Rm.Force {
 		slog.Debug("show orphaned trashinfo", "files", orphanedFiles)
@@ -235,3 +451,106 @@ func (c *CLI) removeOrphanedMetadata() error {
 	fmt.Printf("Successfully removed %d orphaned metadata files.\n", len(orphanedFiles))
 	return nil
 }
+
+// findOrphanedData collects data files without .trashinfo from all XDG trash directories
+func findOrphanedData() ([]xdg.OrphanedDataFile, error) {
+	trashDirs, err := xdg.FindAllTrashDirectories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash dirs: %w", err)
+	}
+
+	var orphanedFiles []xdg.OrphanedDataFile
+	for _, trashDir := range trashDirs {
+		slog.Debug("finding orphaned data", "trashDir", trashDir)
+		files, err := xdg.FindOrphanedDataFiles(trashDir)
+		if err != nil {
+			slog.Error("failed to find orphaned data in trash dir", "dir", trashDir, "error", err)
+			continue
+		}
+		orphanedFiles = append(orphanedFiles, files...)
+	}
+
+	return orphanedFiles, nil
+}
+
+// listOrphanedData lists files in the trash that have lost their .trashinfo files.
+// Such files never show up in the restore UI and are never pruned by age,
+// so they use disk space until adopted or removed by hand.
+func (c *CLI) listOrphanedData() error {
+	slog.Debug("listing orphaned data files")
+
+	orphanedFiles, err := findOrphanedData()
+	if err != nil {
+		return err
+	}
+
+	if len(orphanedFiles) == 0 {
+		fmt.Println("No orphaned data files found.")
+		return nil
+	}
+
+	table.PrintFiles(orphanedFiles, table.PrintOptions{
+		ShowRelativeTime: true,
+		Order:            table.SortDesc,
+	})
+	fmt.Println()
+	fmt.Printf("Found %d data files without metadata.\n", len(orphanedFiles))
+	fmt.Println("Run 'gomi --prune=adopt' to make them restorable.")
+	return nil
+}
+
+// adoptOrphanedData writes synthetic .trashinfo files for data files that have none,
+// so that they can be restored with -b or purged with --prune like any other file.
+func (c *CLI) adoptOrphanedData() error {
+	slog.Debug("adopting orphaned data files")
+
+	orphanedFiles, err := findOrphanedData()
+	if err != nil {
+		return err
+	}
+
+	if len(orphanedFiles) == 0 {
+		fmt.Println("No orphaned data files found.")
+		return nil
+	}
+
+	if c.option.DryRun {
+		for _, file := range orphanedFiles {
+			printPlan("adopt orphaned data %s", file.Path)
+		}
+		return nil
+	}
+
+	// Confirm adoption unless forced
+	if !c.option.Rm.Force {
+		table.PrintFiles(orphanedFiles, table.PrintOptions{
+			ShowRelativeTime: true,
+			Order:            table.SortDesc,
+		})
+		fmt.Println()
+		if !c.prompter.Confirm(fmt.Sprintf("Are you sure you want to adopt %d orphaned data files?", len(orphanedFiles))) {
+			fmt.Println("Operation canceled.")
+			return nil
+		}
+	}
+
+	var failedAdoptions []string
+	for _, file := range orphanedFiles {
+		slog.Debug("adopting orphaned data file", "file", file.Path)
+		if err := xdg.AdoptOrphanedDataFile(file); err != nil {
+			slog.Error("failed to adopt orphaned data file", "file", file.Path, "error", err)
+			failedAdoptions = append(failedAdoptions, file.Path)
+		}
+	}
+
+	if len(failedAdoptions) > 0 {
+		fmt.Printf("Failed to adopt %d files:\n", len(failedAdoptions))
+		for _, file := range failedAdoptions {
+			fmt.Println("-", file)
+		}
+		return fmt.Errorf("some orphaned data files could not be adopted")
+	}
+
+	fmt.Printf("Successfully adopted %d orphaned data files.\n", len(orphanedFiles))
+	return nil
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func TestParsePruneArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"30d"}, false},
+		{[]string{"size>1GB"}, false},
+		{[]string{"size>=10MB", "size<=1GB"}, false},
+		{[]string{"name:*.iso", "30d", "60d"}, false},
+		{[]string{"name~^build-[0-9]+$"}, false},
+		{[]string{"from:/tmp"}, false},
+		{[]string{"size=1GB"}, true},
+		{[]string{"size>big"}, true},
+		{[]string{"size<1"}, true},
+		{[]string{"name:[abc"}, true},
+		{[]string{"name~("}, true},
+		{[]string{"soon"}, true},
+	}
+
+	for _, tt := range tests {
+		_, err := parsePruneArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePruneArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPruneSelector_SelectFiles(t *testing.T) {
+	now := time.Now()
+	files := []*trash.File{
+		{ID: "iso", Name: "ubuntu.iso", OriginalPath: "/home/u/Downloads/ubuntu.iso", Size: 3 << 30, DeletedAt: now.Add(-40 * 24 * time.Hour)},
+		{ID: "new-iso", Name: "debian.iso", OriginalPath: "/home/u/Downloads/debian.iso", Size: 2 << 30, DeletedAt: now.Add(-time.Hour)},
+		{ID: "doc", Name: "notes.txt", OriginalPath: "/home/u/Documents/notes.txt", Size: 100, DeletedAt: now.Add(-40 * 24 * time.Hour)},
+		{ID: "prefix", Name: "x", OriginalPath: "/home/u/Downloads2/x", Size: 100, DeletedAt: now.Add(-40 * 24 * time.Hour)},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"size", []string{"size>1GB"}, []string{"iso", "new-iso"}},
+		{"size and age", []string{"size>1GB", "30d"}, []string{"iso"}},
+		{"name", []string{"name:*.iso"}, []string{"iso", "new-iso"}},
+		{"regexp", []string{"name~^notes"}, []string{"doc"}},
+		{"from", []string{"from:/home/u/Downloads"}, []string{"iso", "new-iso"}},
+		{"from and small", []string{"from:/home/u", "size<1KB"}, []string{"doc", "prefix"}},
+		{"age range", []string{"0d", "1d"}, []string{"new-iso"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel, err := parsePruneArgs(tt.args)
+			if err != nil {
+				t.Fatalf("parsePruneArgs() error = %v", err)
+			}
+			got := sel.selectFiles(files)
+			if len(got) != len(tt.want) {
+				t.Fatalf("selected %d files, want %v", len(got), tt.want)
+			}
+			for i, f := range got {
+				if f.ID != tt.want[i] {
+					t.Errorf("selected[%d] = %q, want %q", i, f.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePruneArgs_InvalidSelector(t *testing.T) {
+	if _, err := parsePruneArgs([]string{"size~1GB"}); !errors.Is(err, ErrInvalidSelector) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidSelector)
+	}
+}
+
+func TestPruneSelector_KeepLast(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	files := []*trash.File{
+		{ID: "notes-1", OriginalPath: "/home/u/notes.md", DeletedAt: now.Add(-1 * day)},
+		{ID: "notes-40", OriginalPath: "/home/u/notes.md", DeletedAt: now.Add(-40 * day)},
+		{ID: "notes-10", OriginalPath: "/home/u/notes.md", DeletedAt: now.Add(-10 * day)},
+		{ID: "notes-50", OriginalPath: "/home/u/notes.md", DeletedAt: now.Add(-50 * day)},
+		{ID: "build-60", OriginalPath: "/home/u/build", DeletedAt: now.Add(-60 * day)},
+		{ID: "build-5", OriginalPath: "/home/u/build", DeletedAt: now.Add(-5 * day)},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"keep newest 2", []string{"keep-last=2"}, []string{"notes-40", "notes-50"}},
+		{"keep newest 1", []string{"keep-last=1"}, []string{"build-60", "notes-10", "notes-40", "notes-50"}},
+		{"keep newest 3", []string{"keep-last=3"}, []string{"notes-50"}},
+		{"with age floor", []string{"keep-last=1", "older=30d"}, []string{"build-60", "notes-40", "notes-50"}},
+		{"with name", []string{"keep-last=1", "name:notes.*"}, []string{"notes-10", "notes-40", "notes-50"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, f := range files {
+				f.Name = filepath.Base(f.OriginalPath)
+				f.Size = 1
+			}
+			sel, err := parsePruneArgs(tt.args)
+			if err != nil {
+				t.Fatalf("parsePruneArgs() error = %v", err)
+			}
+			var got []string
+			for _, f := range sel.selectFiles(files) {
+				got = append(got, f.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePruneArgs_InvalidKeepLast(t *testing.T) {
+	for _, arg := range []string{"keep-last=0", "keep-last=-1", "keep-last=x", "older=soon"} {
+		if _, err := parsePruneArgs([]string{arg}); err == nil {
+			t.Errorf("parsePruneArgs(%q) should return error", arg)
+		}
+	}
+}
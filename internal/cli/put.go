@@ -6,11 +6,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
+	"runtime"
 	"sync"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/babarot/gomi/internal/trash"
 	"github.com/babarot/gomi/internal/utils/fs"
 )
 
@@ -19,99 +20,243 @@ func (c *CLI) Put(args []string) error {
 	slog.Debug("cli.put started")
 	defer slog.Debug("cli.put finished")
 
-	if len(args) == 0 {
+	if len(args) == 0 && c.option.FilesFrom == "" {
 		return errors.New("too few arguments")
 	}
 
-	// Use a thread-safe slice to track failed files
+	files, closeList, err := c.fileArgs(args)
+	if err != nil {
+		return err
+	}
+	defer closeList()
+
+	// total is the number of files, if known before reading the list
+	total := len(args)
+	if c.option.FilesFrom != "" {
+		total = 0
+	}
+
+	if c.config.Core.Put.ConfirmOver.Enabled() && !c.option.Rm.Force && !c.option.DryRun {
+		// The batch is measured before anything is moved
+		var ok bool
+		files, ok, err = c.confirmPut(files)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Operation canceled.")
+			return nil
+		}
+	}
+
+	// Trimming the trash to the retention policy comes after the new files
+	defer c.autoEnforceRetention()
+
+	if c.strictRm() {
+		return c.putStrict(files)
+	}
+
+	// Compile forbidden_paths and rules once for all workers
+	c.forbiddenPaths()
+	c.pathRules()
+
+	// Use thread-safe slices to track failed files
 	var (
 		eg     errgroup.Group
-		failed = &syncStringSlice{}
+		failed = &syncSlice[string]{}
+		errs   = &syncSlice[error]{}
 	)
+	if c.option.DryRun {
+		// Print the plan in the order of the arguments
+		eg.SetLimit(1)
+	} else {
+		// This also reads --files-from only as fast as files are moved
+		eg.SetLimit(c.putWorkers())
+	}
 
-	for _, arg := range args {
+	c.progress.begin(total)
+
+	var readErr error
+	for arg, err := range files {
+		if err != nil {
+			readErr = err
+			break
+		}
 		eg.Go(func() error {
-			return c.processFile(arg, failed)
+			defer c.progress.finish()
+			// Every file is processed, whatever happens to the others
+			if err := c.processFile(arg, failed); err != nil {
+				errs.Append(err)
+			}
+			return nil
 		})
 	}
 
 	// Wait for all goroutines to complete
-	if err := eg.Wait(); err != nil {
+	_ = eg.Wait()
+	c.progress.end()
+	if err := newBatchError(errs.Get()); err != nil {
 		return err
 	}
-
-	if failedFiles := failed.Get(); len(failedFiles) > 0 {
-		return fmt.Errorf("failed to process files %v", failedFiles)
-	}
-
-	return nil
+	return readErr
 }
 
 // processFile handles the logic for moving a single file to trash
-func (c *CLI) processFile(arg string, failed *syncStringSlice) error {
-	// Expand path (replace environment variables)
-	expandedPath, err := expandPath(arg)
-	if err != nil {
+func (c *CLI) processFile(arg string, failed *syncSlice[string]) error {
+	rec := reportRecord{Op: "put", Arg: arg, Outcome: outcomeFailed}
+	fail := func(err error) error {
 		failed.Append(arg)
-		return fmt.Errorf("failed to expand path: %w", err)
+		c.report.add(rec, err)
+		return err
 	}
 
-	// Check for forbidden paths
-	if c.isForbiddenPath(expandedPath) {
-		failed.Append(arg)
-		return fmt.Errorf("refusing to remove forbidden path: %q", arg)
+	// Expand path (replace environment variables)
+	expandedPath, err := expandPath(arg)
+	if err != nil {
+		return fail(fmt.Errorf("failed to expand path: %w", err))
 	}
 
 	// Check path safety
 	unsafe, err := fs.IsUnsafePath(expandedPath)
 	if err != nil {
-		failed.Append(arg)
-		return fmt.Errorf("failed to check path safety: %w", err)
+		return fail(fmt.Errorf("failed to check path safety: %w", err))
 	}
 	if unsafe {
-		failed.Append(arg)
-		return fmt.Errorf("refusing to remove unsafe path: %q", arg)
+		rec.Outcome = outcomeForbidden
+		return fail(fmt.Errorf("%w: %q", ErrUnsafePath, arg))
 	}
 
 	// Get absolute path
 	path, err := filepath.Abs(expandedPath)
 	if err != nil {
-		failed.Append(arg)
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return fail(fmt.Errorf("failed to get absolute path: %w", err))
+	}
+	rec.Path = path
+
+	// Check for forbidden paths
+	ok, err := c.checkProtected(arg, path)
+	if err != nil {
+		rec.Outcome = outcomeForbidden
+		return fail(err)
+	}
+	if !ok {
+		rec.Outcome = outcomeSkipped
+		c.report.add(rec, nil)
+		return nil
 	}
 
 	// Check if file exists (use Lstat to handle broken symlinks)
-	if _, err := os.Lstat(path); os.IsNotExist(err) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		err := fmt.Errorf("%s: %w", arg, ErrNoSuchFile)
 		if !c.option.Rm.Force {
-			failed.Append(arg)
-			return fmt.Errorf("%s: no such file or directory", arg)
+			return fail(err)
 		}
 		if c.option.Rm.Verbose {
-			fmt.Fprintf(os.Stderr, "skipping %s: no such file or directory\n", arg)
+			fmt.Fprintf(os.Stderr, "skipping %v\n", err)
+		}
+		rec.Outcome = outcomeSkipped
+		c.report.add(rec, err)
+		return nil
+	}
+
+	if c.strictRm() {
+		if err != nil {
+			return fail(fmt.Errorf("cannot remove '%s': %w", arg, err))
+		}
+		ok, err := c.checkRm(arg, path, info)
+		if err != nil {
+			return fail(err)
+		}
+		if !ok {
+			rec.Outcome = outcomeSkipped
+			c.report.add(rec, nil)
+			return nil
+		}
+	}
+
+	// Apply the first matching rule
+	rule := c.pathRules().match(path)
+	if rule != nil {
+		rec.Rule = rule.entry
+		slog.Debug("matched rule", "path", path, "rule", rule.entry, "action", rule.action)
+	}
+	switch {
+	case rule == nil:
+	case rule.action == ruleRefuse:
+		rec.Outcome = outcomeForbidden
+		return fail(fmt.Errorf("%w: %q (rule %s)", ErrForbiddenPath, arg, rule.entry))
+	case rule.action == ruleDelete:
+		return c.deleteByRule(arg, path, info, rule, rec, fail)
+	}
+
+	if c.option.DryRun {
+		plan, err := c.planPut(arg, path, rule)
+		if err != nil {
+			return fail(err)
 		}
+		rec.Outcome = outcomePlanned
+		rec.Backend, rec.TrashPath = plan.Backend.String(), plan.TrashPath
+		c.report.add(rec, nil)
 		return nil
 	}
 
 	// Move to trash
-	err = c.trash.Put(path)
+	c.progress.start(path)
+	file, err := c.putFileByRule(path, rule)
 	if err != nil {
-		if !c.option.Rm.Force {
-			failed.Append(arg)
-			return fmt.Errorf("failed to move to trash: %w", err)
+		err = fmt.Errorf("failed to move to trash: %w", err)
+		// rm -f only ignores nonexistent files
+		if !c.option.Rm.Force || c.strictRm() {
+			return fail(err)
 		}
 		if c.option.Rm.Verbose {
 			fmt.Fprintf(os.Stderr, "failed to move %s to trash: %v\n", arg, err)
 		}
+		rec.Outcome = outcomeSkipped
+		c.report.add(rec, err)
 		return nil
 	}
 
-	if c.option.Rm.Verbose {
-		fmt.Printf("moved to trash: %s\n", path)
+	// Directories are counted by the bytes copied across devices, if any
+	var size int64
+	if info != nil && info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	c.progress.add(path, size)
+
+	rec.Outcome = outcomeTrashed
+	c.report.add(rec.withFile(file), nil)
+
+	if c.option.Rm.Verbose && c.report == nil {
+		fmt.Printf("moved to trash: %s%s\n", path, ruleNote(rule))
 	}
 
 	return nil
 }
 
+// putFile moves path to trash. The returned file tells where it went if
+// the trash implements trash.Putter.
+func (c *CLI) putFile(path string) (*trash.File, error) {
+	if putter, ok := c.trash.(trash.Putter); ok {
+		return putter.PutFile(path)
+	}
+	return nil, c.trash.Put(path)
+}
+
+// putWorkers returns the number of files moved at a time: --workers, or
+// core.put.workers, or the number of CPUs
+func (c *CLI) putWorkers() int {
+	switch {
+	case c.option.Workers > 0:
+		return c.option.Workers
+	case c.config.Core.Put.Workers > 0:
+		return c.config.Core.Put.Workers
+	default:
+		return runtime.NumCPU()
+	}
+}
+
 // expandPath resolves a file path to its clean form.
 // It does NOT expand environment variables because file arguments
 // should be treated literally — a file named "$foo" must not be
@@ -121,39 +266,22 @@ func expandPath(path string) (string, error) {
 	return filepath.Clean(path), nil
 }
 
-// isForbiddenPath checks if the given path is in the forbidden paths list
-func (c *CLI) isForbiddenPath(path string) bool {
-	path = filepath.Clean(path)
-
-	for _, forbiddenPath := range c.config.Core.Trash.ForbiddenPaths {
-		// Expand forbidden path with environment variables
-		expandedForbiddenPath := os.ExpandEnv(forbiddenPath)
-		expandedForbiddenPath = filepath.Clean(expandedForbiddenPath)
-
-		// Check for exact match or sub-path
-		if path == expandedForbiddenPath || strings.HasPrefix(path, expandedForbiddenPath+string(filepath.Separator)) {
-			return true
-		}
-	}
-	return false
-}
-
-// syncStringSlice is a thread-safe slice for storing strings
-type syncStringSlice struct {
+// syncSlice is a thread-safe slice
+type syncSlice[T any] struct {
 	mu    sync.Mutex
-	items []string
+	items []T
 }
 
 // Append adds an item to the slice in a thread-safe manner
-func (s *syncStringSlice) Append(item string) {
+func (s *syncSlice[T]) Append(item T) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.items = append(s.items, item)
 }
 
 // Get returns a copy of the slice
-func (s *syncStringSlice) Get() []string {
+func (s *syncSlice[T]) Get() []T {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return append([]string(nil), s.items...)
+	return append([]T(nil), s.items...)
 }
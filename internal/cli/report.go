@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+var (
+	// ErrForbiddenPath is returned for arguments in core.trash.forbidden_paths
+	ErrForbiddenPath = errors.New("refusing to remove forbidden path")
+
+	// ErrUnsafePath is returned for arguments such as . and ..
+	ErrUnsafePath = errors.New("refusing to remove unsafe path")
+
+	// ErrNoSuchFile is returned for arguments that do not exist
+	ErrNoSuchFile = errors.New("no such file or directory")
+)
+
+// Error codes of failures found by the CLI before the trash is involved.
+// The others come from trash.Code.
+const (
+	codeForbiddenPath trash.ErrorCode = "forbidden_path"
+	codeUnsafePath    trash.ErrorCode = "unsafe_path"
+)
+
+// Outcomes of the records of --report
+const (
+	outcomeTrashed   = "trashed"
+	outcomeRestored  = "restored"
+	outcomeDeleted   = "deleted"
+	outcomePlanned   = "planned" // with --dry-run
+	outcomeSkipped   = "skipped"
+	outcomeForbidden = "forbidden"
+	outcomeFailed    = "failed"
+)
+
+// Exit codes by failure class. With core.rm_compat: strict, failures of
+// put exit with 1 as in rm.
+const (
+	exitFailure     = 1 // any other error, or failures of different classes
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitPermission  = 4
+	exitForbidden   = 5
+	exitFileExists  = 6
+	exitCrossDevice = 7
+	exitStorage     = 8
+)
+
+// ErrReported is returned when the failures were already printed, so
+// that only the exit status is left to set, as rm does
+var ErrReported = errors.New("failures were reported")
+
+// reportRecord is the result of put, restore or prune for one file
+type reportRecord struct {
+	Op        string          `json:"op"`
+	Arg       string          `json:"arg,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Outcome   string          `json:"outcome"`
+	Backend   string          `json:"backend,omitempty"`
+	TrashPath string          `json:"trash_path,omitempty"`
+	Rule      string          `json:"rule,omitempty"`
+	Code      trash.ErrorCode `json:"code,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// withFile sets the fields of rec known from the trashed file
+func (rec reportRecord) withFile(file *trash.File) reportRecord {
+	if file != nil {
+		rec.Backend = file.Backend.String()
+		rec.TrashPath = file.TrashPath
+	}
+	return rec
+}
+
+// restoreRecord returns the record of restoring file to dst
+func restoreRecord(file *trash.File, dst, outcome string) reportRecord {
+	return reportRecord{
+		Op:      "restore",
+		Arg:     file.GetOriginalPath(),
+		Path:    dst,
+		Outcome: outcome,
+	}.withFile(file)
+}
+
+// pruneRecord returns the record of permanently deleting file
+func pruneRecord(file *trash.File, outcome string) reportRecord {
+	return reportRecord{
+		Op:      "prune",
+		Path:    file.GetOriginalPath(),
+		Outcome: outcome,
+	}.withFile(file)
+}
+
+// reporter writes --report records as JSON lines. All methods do nothing
+// on a nil *reporter, so that callers need not check for --report.
+type reporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// newReporter returns a reporter writing to w
+func newReporter(w io.Writer) *reporter {
+	return &reporter{enc: json.NewEncoder(w)}
+}
+
+// add writes rec, with the code and message of err if it is not nil
+func (r *reporter) add(rec reportRecord, err error) {
+	if r == nil {
+		return
+	}
+	if err != nil {
+		rec.Code = errorCode(err)
+		rec.Error = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(rec)
+}
+
+// errorCode classifies err for --report and the exit code
+func errorCode(err error) trash.ErrorCode {
+	switch {
+	case errors.Is(err, ErrForbiddenPath):
+		return codeForbiddenPath
+	case errors.Is(err, ErrUnsafePath):
+		return codeUnsafePath
+	case errors.Is(err, ErrNoSuchFile), errors.Is(err, ErrNoMatch):
+		return trash.CodeNotFound
+	default:
+		return trash.Code(err)
+	}
+}
+
+// batchError holds the failures of several files
+type batchError struct {
+	errs []error
+}
+
+// newBatchError returns nil for no errors, the error itself for one,
+// and a batchError for more
+func newBatchError(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return &batchError{errs: errs}
+	}
+}
+
+func (e *batchError) Error() string {
+	return fmt.Sprintf("%v (and %d more failures)", e.errs[0], len(e.errs)-1)
+}
+
+func (e *batchError) Unwrap() []error {
+	return e.errs
+}
+
+// ExitCode returns the exit status for err returned by Run: 0 for nil,
+// a distinct code per failure class, and 1 for other errors or for
+// failures of different classes
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var batch *batchError
+	if !errors.As(err, &batch) {
+		return exitCodeOf(err)
+	}
+	code := exitCodeOf(batch.errs[0])
+	for _, err := range batch.errs[1:] {
+		if exitCodeOf(err) != code {
+			return exitFailure
+		}
+	}
+	return code
+}
+
+// exitCodeOf returns the exit status for a single failure
+func exitCodeOf(err error) int {
+	var flagsErr *flags.Error
+	if errors.As(err, &flagsErr) {
+		return exitUsage
+	}
+	switch errorCode(err) {
+	case trash.CodeNotFound:
+		return exitNotFound
+	case trash.CodePermissionDenied:
+		return exitPermission
+	case codeForbiddenPath, codeUnsafePath, trash.CodeVetoed:
+		return exitForbidden
+	case trash.CodeFileExists:
+		return exitFileExists
+	case trash.CodeCrossDevice:
+		return exitCrossDevice
+	case trash.CodeStorageNotReady, trash.CodeInvalidStorage:
+		return exitStorage
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// readRecords parses the JSON lines written by a reporter, ordered by
+// argument and path
+func readRecords(t *testing.T, buf *bytes.Buffer) []reportRecord {
+	t.Helper()
+	var records []reportRecord
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var rec reportRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("invalid record: %v", err)
+		}
+		records = append(records, rec)
+	}
+	slices.SortFunc(records, func(a, b reportRecord) int {
+		return strings.Compare(a.Arg+a.Path, b.Arg+b.Path)
+	})
+	return records
+}
+
+func TestPut_Report(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	forbidden := filepath.Join(dir, "keep")
+	for _, path := range []string{file, forbidden} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Core.Trash.ForbiddenPaths = []config.ForbiddenPath{{Path: forbidden}}
+	var buf bytes.Buffer
+	cli := CLI{config: cfg, trash: &fakeTrash{}, report: newReporter(&buf)}
+
+	missing := filepath.Join(dir, "missing")
+	err := cli.Put([]string{file, forbidden, missing})
+	if err == nil {
+		t.Fatal("Put() should fail")
+	}
+	if got := ExitCode(err); got != exitFailure {
+		t.Errorf("ExitCode() = %d, want %d for failures of different classes", got, exitFailure)
+	}
+
+	want := []reportRecord{
+		{Op: "put", Arg: file, Path: file, Outcome: outcomeTrashed},
+		{Op: "put", Arg: forbidden, Path: forbidden, Outcome: outcomeForbidden, Code: codeForbiddenPath, Error: fmt.Sprintf("refusing to remove forbidden path: %q (%s)", forbidden, forbidden)},
+		{Op: "put", Arg: missing, Path: missing, Outcome: outcomeFailed, Code: trash.CodeNotFound, Error: missing + ": no such file or directory"},
+	}
+	if got := readRecords(t, &buf); !slices.Equal(got, want) {
+		t.Errorf("records = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestPut_ReportDryRun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}, report: newReporter(&buf)}
+	cli.option.DryRun = true
+	if err := cli.Put([]string{file}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	want := []reportRecord{{Op: "put", Arg: file, Path: file, Outcome: outcomePlanned, Backend: "xdg", TrashPath: "/trash/files/a.txt"}}
+	if got := readRecords(t, &buf); !slices.Equal(got, want) {
+		t.Errorf("records = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestRestoreWithPolicy_Report(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(dst, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}, report: newReporter(&buf)}
+	cli.option.RestoreBy.OnConflict = "skip"
+	file := &trash.File{ID: "a.txt", OriginalPath: dst, TrashPath: "/trash/files/a.txt", Backend: trash.StorageTypeXDG}
+	if err := cli.restoreWithPolicy(file, dst); err != nil {
+		t.Fatalf("restoreWithPolicy() error = %v", err)
+	}
+
+	got := readRecords(t, &buf)
+	if len(got) != 1 {
+		t.Fatalf("got %d records, want 1", len(got))
+	}
+	if got[0].Op != "restore" || got[0].Outcome != outcomeSkipped || got[0].Code != trash.CodeFileExists || got[0].Backend != "xdg" {
+		t.Errorf("record = %+v, want a skipped restore with code %s", got[0], trash.CodeFileExists)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	notFound := fmt.Errorf("a: %w", ErrNoSuchFile)
+	permission := fmt.Errorf("failed to move to trash: %w",
+		trash.NewStorageError("put", "/a", &os.PathError{Op: "rename", Path: "/a", Err: syscall.EACCES}))
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"other", errors.New("boom"), exitFailure},
+		{"usage", &flags.Error{Type: flags.ErrUnknownFlag}, exitUsage},
+		{"not found", notFound, exitNotFound},
+		{"no match", fmt.Errorf("restore: %w", ErrNoMatch), exitNotFound},
+		{"permission", permission, exitPermission},
+		{"forbidden", fmt.Errorf("%w: %q", ErrForbiddenPath, "/etc"), exitForbidden},
+		{"unsafe", fmt.Errorf("%w: %q", ErrUnsafePath, ".."), exitForbidden},
+		{"file exists", fmt.Errorf("/a: %w", trash.ErrFileExists), exitFileExists},
+		{"cross device", trash.ErrCrossDevice, exitCrossDevice},
+		{"storage", trash.ErrStorageNotReady, exitStorage},
+		{"batch of one class", newBatchError([]error{notFound, notFound}), exitNotFound},
+		{"batch of several classes", newBatchError([]error{notFound, permission}), exitFailure},
+		{"wrapped batch", fmt.Errorf("some files could not be removed: %w", newBatchError([]error{permission, permission})), exitPermission},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
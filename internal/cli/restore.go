@@ -4,11 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
 
 	"github.com/babarot/gomi/internal/trash"
 	"github.com/babarot/gomi/internal/ui"
+	"github.com/babarot/gomi/internal/utils/shell"
 )
 
 // Restore handles the restoration of files from trash
@@ -35,7 +42,7 @@ func (c *CLI) Restore() error {
 	}
 
 	// Show UI for file selection
-	selected, err := ui.Render(c.trash, filtered, ui.RenderOptions{
+	result, err := ui.Render(c.trash, filtered, ui.RenderOptions{
 		Config:        c.config.UI,
 		DeleteEnabled: c.config.Core.PermanentDelete.Enable,
 	})
@@ -44,12 +51,35 @@ func (c *CLI) Restore() error {
 	}
 
 	// If no files were selected, exit early
+	selected := result.Files
 	if len(selected) == 0 {
 		return nil
 	}
 
-	for _, file := range selected {
-		if err := c.restoreFile(file); err != nil {
+	dir := c.option.RestoreBy.RestoreTo
+	if result.RestoreTo && dir == "" {
+		dir, err = c.prompter.InputDirectory()
+		if err != nil {
+			if errors.Is(err, ui.ErrInputCanceled) {
+				c.printVerbose("Canceled! No directory input.\n")
+				return nil
+			}
+			return fmt.Errorf("failed to get directory: %w", err)
+		}
+	}
+
+	dsts, err := destinations(selected, dir)
+	if err != nil {
+		return err
+	}
+	for i, file := range selected {
+		restore := c.restoreFile
+		if c.option.DryRun {
+			restore = c.planRestore
+		}
+		warnUnknownOrigin(file, dsts[i])
+		if err := restore(file, dsts[i]); err != nil {
+			c.report.add(restoreRecord(file, dsts[i], outcomeFailed), err)
 			return fmt.Errorf("failed to restore file '%s': %w", file.Name, err)
 		}
 	}
@@ -57,6 +87,68 @@ func (c *CLI) Restore() error {
 	return nil
 }
 
+// destinations returns where each file is restored to. Without dir, that
+// is the original path. With dir, files are placed under dir with the
+// directory their original paths have in common stripped, so that
+// /a/b/x and /a/c/y become dir/b/x and dir/c/y.
+func destinations(files []*trash.File, dir string) ([]string, error) {
+	dsts := make([]string, len(files))
+	if dir == "" {
+		for i, file := range files {
+			dsts[i] = file.GetOriginalPath()
+		}
+		return dsts, nil
+	}
+
+	dir, err := shell.ExpandHome(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %q: %w", dir, err)
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.GetOriginalPath()
+	}
+	common := commonDir(paths)
+	for i, path := range paths {
+		rel, err := filepath.Rel(common, path)
+		if err != nil {
+			// No common directory, e.g. paths on different Windows volumes
+			rel = strings.TrimPrefix(path, filepath.VolumeName(path))
+		}
+		dsts[i] = filepath.Join(dir, rel)
+	}
+	return dsts, nil
+}
+
+// commonDir returns the deepest directory containing all of paths
+func commonDir(paths []string) string {
+	dir := filepath.Dir(paths[0])
+	for _, path := range paths[1:] {
+		for !isWithin(path, dir) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return dir
+}
+
+// isWithin reports whether path is below dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // filterFiles applies configured filters to the list of files
 func (c *CLI) filterFiles(files []*trash.File) []*trash.File {
 	var filtered []*trash.File
@@ -77,28 +169,29 @@ func (c *CLI) filterFiles(files []*trash.File) []*trash.File {
 	return filtered
 }
 
-// restoreFile handles the restoration of a single file
-func (c *CLI) restoreFile(file *trash.File) error {
-	originalPath := file.OriginalPath
+// restoreFile handles the restoration of a single file to dst
+func (c *CLI) restoreFile(file *trash.File, dst string) error {
+	originalPath := dst
 
-	// Check if the file exists at the original location
+	// Check if the file exists at the destination
 	if _, err := os.Stat(originalPath); err == nil {
 		// File exists at original location, ask for new name if necessary
 		newName, err := c.prompter.InputFilename(file)
 		if err != nil {
 			if errors.Is(err, ui.ErrInputCanceled) {
 				c.printVerbose("Canceled! No new filename input.\n")
+				c.report.add(restoreRecord(file, dst, outcomeSkipped), nil)
 				return nil
 			}
 			return fmt.Errorf("failed to get new filename: %w", err)
 		}
 		originalPath = filepath.Join(filepath.Dir(originalPath), newName)
-		file.OriginalPath = originalPath
 	}
 
 	// If configured, ask for confirmation
 	if c.config.Core.Restore.Confirm && !c.prompter.Confirm(fmt.Sprintf("OK to restore? %s", filepath.Base(originalPath))) {
 		c.printVerbose("Replied no, canceled!\n")
+		c.report.add(restoreRecord(file, originalPath, outcomeSkipped), nil)
 		return nil
 	}
 
@@ -107,6 +200,7 @@ func (c *CLI) restoreFile(file *trash.File) error {
 		msg := fmt.Sprintf("Caution! The same name already exists. Even so okay to restore? %s", filepath.Base(originalPath))
 		if !c.prompter.Confirm(msg) {
 			c.printVerbose("Replied no, canceled!\n")
+			c.report.add(restoreRecord(file, originalPath, outcomeSkipped), nil)
 			return nil
 		}
 	}
@@ -117,12 +211,243 @@ func (c *CLI) restoreFile(file *trash.File) error {
 	}
 
 	c.printVerbose("Restored '%s' to %s\n", file.Name, originalPath)
+	c.report.add(restoreRecord(file, originalPath, outcomeRestored), nil)
 	return nil
 }
 
-// printVerbose logs the message if verbose is true
+// warnUnknownOrigin warns when file goes back to its recorded original
+// path although that path is only a placeholder
+func warnUnknownOrigin(file *trash.File, dst string) {
+	if file.UnknownOrigin && dst == file.GetOriginalPath() {
+		fmt.Fprintf(os.Stderr, "warning: original location of %s is unknown, restoring to %s\n", file.Name, dst)
+	}
+}
+
+// printVerbose logs the message if verbose is true. It is silent with
+// --report, which prints the results instead.
 func (c *CLI) printVerbose(msg string, args ...any) {
-	if c.config.Core.Restore.Verbose {
+	if c.config.Core.Restore.Verbose && c.report == nil {
 		fmt.Printf(msg, args...)
 	}
 }
+
+var (
+	// ErrNoMatch is returned when no trashed file matches the selector
+	ErrNoMatch = errors.New("no matching file in trash")
+
+	// ErrSelectorCombination is returned when more than one of
+	// --restore-path, --restore-glob and --restore-id is given
+	ErrSelectorCombination = errors.New("--restore-path, --restore-glob and --restore-id cannot be combined")
+
+	// ErrAmbiguousID is returned when an ID matches files in several trashes
+	ErrAmbiguousID = errors.New("ID matches more than one file, use the trash path instead")
+)
+
+// deletedAtLayouts are the accepted --deleted-at formats with the
+// precision each of them matches at
+var deletedAtLayouts = []struct {
+	layout    string
+	precision time.Duration
+}{
+	{time.RFC3339, time.Second},
+	{"2006-01-02T15:04:05", time.Second},
+	{"2006-01-02 15:04:05", time.Second},
+	{"2006-01-02T15:04", time.Minute},
+	{"2006-01-02 15:04", time.Minute},
+	{"2006-01-02", 24 * time.Hour},
+}
+
+// RestoreSelected restores files chosen by --restore-path, --restore-glob
+// or --restore-id without the interactive UI, to their original location
+// or under --restore-to. When the destination exists, the --on-conflict
+// policy applies, or the user is asked as with -b.
+func (c *CLI) RestoreSelected() error {
+	slog.Debug("cli.restore-selected started")
+	defer slog.Debug("cli.restore-selected finished")
+
+	files, err := c.trash.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash contents: %w", err)
+	}
+
+	selected, err := selectFiles(c.filterFiles(files), c.option.RestoreBy)
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	dsts, err := destinations(selected, c.option.RestoreBy.RestoreTo)
+	if err != nil {
+		return err
+	}
+	for i, file := range selected {
+		restore := c.restoreFile
+		switch {
+		case c.option.DryRun:
+			restore = c.planRestore
+		case c.option.RestoreBy.OnConflict != "":
+			restore = c.restoreWithPolicy
+		}
+		warnUnknownOrigin(file, dsts[i])
+		if err := restore(file, dsts[i]); err != nil {
+			c.report.add(restoreRecord(file, dsts[i], outcomeFailed), err)
+			return fmt.Errorf("failed to restore file '%s': %w", file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// selectFiles picks the files to restore. Copies of the same original path
+// are told apart by --restore-version and --deleted-at.
+func selectFiles(files []*trash.File, opt RestoreOption) ([]*trash.File, error) {
+	var given int
+	for _, s := range []string{opt.Path, opt.Glob, opt.ID} {
+		if s != "" {
+			given++
+		}
+	}
+	if given > 1 {
+		return nil, ErrSelectorCombination
+	}
+
+	// Group the candidates by their original path
+	groups := make(map[string][]*trash.File)
+	switch {
+	case opt.ID != "":
+		var matched []*trash.File
+		for _, f := range files {
+			if f.ID == opt.ID || f.TrashPath == opt.ID {
+				matched = append(matched, f)
+			}
+		}
+		if len(matched) > 1 {
+			return nil, fmt.Errorf("%s: %w", opt.ID, ErrAmbiguousID)
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("%s: %w", opt.ID, ErrNoMatch)
+		}
+		return matched, nil
+
+	case opt.Path != "":
+		path, err := filepath.Abs(opt.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		for _, f := range files {
+			if f.GetOriginalPath() == path {
+				groups[path] = append(groups[path], f)
+			}
+		}
+
+	case opt.Glob != "":
+		g, err := glob.Compile(opt.Glob, filepath.Separator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", opt.Glob, err)
+		}
+		matchName := !strings.ContainsRune(opt.Glob, filepath.Separator)
+		for _, f := range files {
+			path := f.GetOriginalPath()
+			if g.Match(path) || (matchName && g.Match(filepath.Base(path))) {
+				groups[path] = append(groups[path], f)
+			}
+		}
+	}
+
+	var selected []*trash.File
+	for _, path := range slices.Sorted(maps.Keys(groups)) {
+		file, err := pickCopy(groups[path], opt.Version, opt.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		selected = append(selected, file)
+	}
+	if len(selected) == 0 {
+		return nil, ErrNoMatch
+	}
+	return selected, nil
+}
+
+// pickCopy returns the Nth most recent copy (1-based, 0 means newest)
+// among those deleted at deletedAt, if given
+func pickCopy(copies []*trash.File, version int, deletedAt string) (*trash.File, error) {
+	if deletedAt != "" {
+		start, end, err := parseDeletedAt(deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		copies = slices.DeleteFunc(slices.Clone(copies), func(f *trash.File) bool {
+			return f.DeletedAt.Before(start) || !f.DeletedAt.Before(end)
+		})
+	}
+
+	slices.SortFunc(copies, func(a, b *trash.File) int {
+		return b.DeletedAt.Compare(a.DeletedAt)
+	})
+
+	if version <= 0 {
+		version = 1
+	}
+	if version > len(copies) {
+		if len(copies) == 0 {
+			return nil, ErrNoMatch
+		}
+		return nil, fmt.Errorf("only %d copies in trash: %w", len(copies), ErrNoMatch)
+	}
+	return copies[version-1], nil
+}
+
+// parseDeletedAt returns the time range matched by a --deleted-at value
+func parseDeletedAt(value string) (time.Time, time.Time, error) {
+	for _, l := range deletedAtLayouts {
+		t, err := time.ParseInLocation(l.layout, value, time.Local)
+		if err == nil {
+			return t, t.Add(l.precision), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid deletion time %q", value)
+}
+
+// restoreWithPolicy restores a file to dst without prompting, resolving
+// a conflict with an existing destination by the --on-conflict policy
+func (c *CLI) restoreWithPolicy(file *trash.File, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		switch c.option.RestoreBy.OnConflict {
+		case "skip":
+			c.printVerbose("Skipped '%s': %s already exists\n", file.Name, dst)
+			c.report.add(restoreRecord(file, dst, outcomeSkipped), fmt.Errorf("%s: %w", dst, trash.ErrFileExists))
+			return nil
+		case "overwrite":
+			// The existing file is trashed rather than deleted,
+			// so that it can be restored in turn
+			if err := c.trash.Put(dst); err != nil {
+				return fmt.Errorf("failed to move existing %s to trash: %w", dst, err)
+			}
+		case "rename":
+			dst = availablePath(dst)
+		default: // fail
+			return fmt.Errorf("%s: %w", dst, trash.ErrFileExists)
+		}
+	}
+
+	if err := c.trash.Restore(file, dst); err != nil {
+		return fmt.Errorf("failed to restore '%s': %w", file.Name, err)
+	}
+
+	c.printVerbose("Restored '%s' to %s\n", file.Name, dst)
+	c.report.add(restoreRecord(file, dst, outcomeRestored), nil)
+	return nil
+}
+
+// availablePath returns the first of path_1.ext, path_2.ext, ... that
+// does not exist yet
+func availablePath(path string) string {
+	// The leading dot of a dotfile is not an extension
+	ext := filepath.Ext(strings.TrimPrefix(filepath.Base(path), "."))
+	stem := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", stem, i, ext)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// fakeTrash records Put, Restore and Remove calls without touching the filesystem
+type fakeTrash struct {
+	mu       sync.Mutex // Put is called concurrently
+	files    []*trash.File
+	put      []string
+	restored map[string]string
+	removed  []string
+	reverted []string
+
+	// failID makes Restore fail for the file with this ID
+	failID string
+}
+
+func (f *fakeTrash) Put(src string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.put = append(f.put, src)
+	return nil
+}
+
+func (f *fakeTrash) PlanPut(src string) (*trash.Plan, error) {
+	return &trash.Plan{Path: src, Backend: trash.StorageTypeXDG, TrashDir: "/trash", TrashPath: "/trash/files/" + filepath.Base(src)}, nil
+}
+
+func (f *fakeTrash) List() ([]*trash.File, error) { return f.files, nil }
+
+func (f *fakeTrash) Remove(file *trash.File) error {
+	f.removed = append(f.removed, file.ID)
+	return nil
+}
+
+func (f *fakeTrash) Restore(file *trash.File, dst string) error {
+	if file.ID == f.failID {
+		return errors.New("restore failed")
+	}
+	if f.restored == nil {
+		f.restored = make(map[string]string)
+	}
+	f.restored[file.ID] = dst
+	return nil
+}
+
+func (f *fakeTrash) RevertRestore(file *trash.File, src string) error {
+	f.reverted = append(f.reverted, src)
+	return nil
+}
+
+func testTrashFiles() []*trash.File {
+	base := time.Date(2024, 6, 15, 10, 30, 0, 0, time.Local)
+	return []*trash.File{
+		{ID: "a.txt", Name: "a.txt", OriginalPath: "/home/u/a.txt", DeletedAt: base},
+		{ID: "a.txt_1", Name: "a.txt", OriginalPath: "/home/u/a.txt", DeletedAt: base.Add(24 * time.Hour)},
+		{ID: "a.txt_2", Name: "a.txt", OriginalPath: "/home/u/a.txt", DeletedAt: base.Add(48 * time.Hour)},
+		{ID: "b.log", Name: "b.log", OriginalPath: "/home/u/logs/b.log", DeletedAt: base, TrashPath: "/t1/files/b.log"},
+		{ID: "b.log", Name: "b.log", OriginalPath: "/media/usb/b.log", DeletedAt: base, TrashPath: "/t2/files/b.log"},
+	}
+}
+
+func TestSelectFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	tests := []struct {
+		name    string
+		opt     RestoreOption
+		want    []string
+		wantErr error
+	}{
+		{"path picks newest", RestoreOption{Path: "/home/u/a.txt"}, []string{"a.txt_2"}, nil},
+		{"path with version", RestoreOption{Path: "/home/u/a.txt", Version: 3}, []string{"a.txt"}, nil},
+		{"path with deleted-at day", RestoreOption{Path: "/home/u/a.txt", DeletedAt: "2024-06-16"}, []string{"a.txt_1"}, nil},
+		{"path with deleted-at second", RestoreOption{Path: "/home/u/a.txt", DeletedAt: "2024-06-15 10:30:00"}, []string{"a.txt"}, nil},
+		{"version out of range", RestoreOption{Path: "/home/u/a.txt", Version: 4}, nil, ErrNoMatch},
+		{"unknown path", RestoreOption{Path: "/home/u/none"}, nil, ErrNoMatch},
+		{"glob on name", RestoreOption{Glob: "*.txt"}, []string{"a.txt_2"}, nil},
+		{"glob on path", RestoreOption{Glob: "/home/u/*/*.log"}, []string{"b.log"}, nil},
+		{"glob on name across dirs", RestoreOption{Glob: "b.*"}, []string{"b.log", "b.log"}, nil},
+		{"id", RestoreOption{ID: "a.txt_1"}, []string{"a.txt_1"}, nil},
+		{"ambiguous id", RestoreOption{ID: "b.log"}, nil, ErrAmbiguousID},
+		{"id by trash path", RestoreOption{ID: "/t2/files/b.log"}, []string{"b.log"}, nil},
+		{"combined selectors", RestoreOption{ID: "x", Glob: "*"}, nil, ErrSelectorCombination},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectFiles(testTrashFiles(), tt.opt)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("selectFiles() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectFiles() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("selectFiles() returned %d files, want %d", len(got), len(tt.want))
+			}
+			for i, f := range got {
+				if f.ID != tt.want[i] {
+					t.Errorf("selectFiles()[%d] = %q, want %q", i, f.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDeletedAt(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"2024-06-15", 24 * time.Hour, false},
+		{"2024-06-15 10:30", time.Minute, false},
+		{"2024-06-15T10:30:00", time.Second, false},
+		{"2024-06-15T10:30:00+09:00", time.Second, false},
+		{"yesterday", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			start, end, err := parseDeletedAt(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDeletedAt() error = %v", err)
+			}
+			if end.Sub(start) != tt.want {
+				t.Errorf("range = %v, want %v", end.Sub(start), tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailablePath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report.pdf", "report_1.pdf", ".bashrc"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"report.pdf", "report_2.pdf"},
+		{".bashrc", ".bashrc_1"},
+		{"noext", "noext_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := availablePath(filepath.Join(dir, tt.input))
+			if want := filepath.Join(dir, tt.want); got != want {
+				t.Errorf("availablePath() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRestoreWithPolicy(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(existing, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy  string
+		wantDst string
+		wantPut bool
+		wantErr error
+	}{
+		{"skip", "", false, nil},
+		{"overwrite", existing, true, nil},
+		{"rename", filepath.Join(dir, "a_1.txt"), false, nil},
+		{"fail", "", false, trash.ErrFileExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ft := &fakeTrash{}
+			cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+			cli.option.RestoreBy.OnConflict = tt.policy
+
+			err := cli.restoreWithPolicy(&trash.File{ID: "a", Name: "a.txt", OriginalPath: existing}, existing)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("restoreWithPolicy() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := ft.restored["a"]; got != tt.wantDst {
+				t.Errorf("restored to %q, want %q", got, tt.wantDst)
+			}
+			if tt.wantPut != (len(ft.put) == 1) {
+				t.Errorf("existing file put to trash = %v, want %v", ft.put, tt.wantPut)
+			}
+		})
+	}
+}
+
+func TestRestoreSelected_NoMatch(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}}
+	cli.option.RestoreBy.Path = "/nonexistent"
+	if err := cli.RestoreSelected(); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("RestoreSelected() error = %v, want %v", err, ErrNoMatch)
+	}
+}
+
+func TestDestinations(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		dir   string
+		want  []string
+	}{
+		{"original paths", []string{"/home/u/a.txt", "/etc/b"}, "", []string{"/home/u/a.txt", "/etc/b"}},
+		{"single file", []string{"/home/u/docs/a.txt"}, "/tmp/r", []string{"/tmp/r/a.txt"}},
+		{"same directory", []string{"/home/u/a.txt", "/home/u/b.txt"}, "/tmp/r", []string{"/tmp/r/a.txt", "/tmp/r/b.txt"}},
+		{"common prefix stripped", []string{"/home/u/src/a.go", "/home/u/docs/x/b.md"}, "/tmp/r", []string{"/tmp/r/src/a.go", "/tmp/r/docs/x/b.md"}},
+		{"directory and its sibling", []string{"/home/u/proj", "/home/u/proj2/c"}, "/tmp/r", []string{"/tmp/r/proj", "/tmp/r/proj2/c"}},
+		{"partial name is not a prefix", []string{"/home/user/a", "/home/username/b"}, "/tmp/r", []string{"/tmp/r/user/a", "/tmp/r/username/b"}},
+		{"nothing in common", []string{"/home/u/a", "/etc/b"}, "/tmp/r", []string{"/tmp/r/home/u/a", "/tmp/r/etc/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []*trash.File
+			for _, p := range tt.paths {
+				files = append(files, &trash.File{OriginalPath: p})
+			}
+			got, err := destinations(files, tt.dir)
+			if err != nil {
+				t.Fatalf("destinations() error = %v", err)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("destinations()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreSelected_RestoreTo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+
+	trashDir := t.TempDir()
+	var files []*trash.File
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(trashDir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, &trash.File{ID: name, Name: name, OriginalPath: "/home/u/src/" + name, TrashPath: path})
+	}
+	files[1].OriginalPath = "/home/u/docs/b.txt"
+
+	ft := &fakeTrash{files: files}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.RestoreBy.Glob = "*.txt"
+	cli.option.RestoreBy.OnConflict = "fail"
+	cli.option.RestoreBy.RestoreTo = "/tmp/scratch"
+
+	if err := cli.RestoreSelected(); err != nil {
+		t.Fatalf("RestoreSelected() error = %v", err)
+	}
+	want := map[string]string{
+		"a.txt": "/tmp/scratch/src/a.txt",
+		"b.txt": "/tmp/scratch/docs/b.txt",
+	}
+	for id, dst := range want {
+		if got := ft.restored[id]; got != dst {
+			t.Errorf("%s restored to %q, want %q", id, got, dst)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/ui/table"
+	"github.com/babarot/gomi/internal/utils/duration"
+	"github.com/babarot/gomi/internal/utils/env"
+)
+
+// defaultRetentionInterval is how often the retention policy is enforced
+// after put, unless core.trash.retention.check_interval is set
+const defaultRetentionInterval = 24 * time.Hour
+
+// ErrNoRetention is returned by --enforce-retention without a policy
+var ErrNoRetention = errors.New("no retention policy, set core.trash.retention or a retention in core.rules")
+
+// retentionPolicy is the compiled core.trash.retention
+type retentionPolicy struct {
+	maxAge   time.Duration
+	maxSize  int64
+	maxItems int
+	pinned   []protectRule
+	rules    *ruleSet
+	interval time.Duration
+}
+
+// newRetentionPolicy compiles cfg, with the retention overrides of rules.
+// Invalid values, which the config validation reports, mean no limit.
+func newRetentionPolicy(cfg config.RetentionConfig, rules *ruleSet) retentionPolicy {
+	p := retentionPolicy{maxItems: cfg.MaxItems, rules: rules, interval: defaultRetentionInterval}
+	if cfg.MaxAge != "" {
+		p.maxAge, _ = duration.Parse(cfg.MaxAge)
+	}
+	if cfg.MaxTotalSize != "" {
+		p.maxSize, _ = units.FromHumanSize(cfg.MaxTotalSize)
+	}
+	if cfg.CheckInterval != "" {
+		if d, err := duration.Parse(cfg.CheckInterval); err == nil {
+			p.interval = d
+		}
+	}
+	for _, path := range cfg.Pinned {
+		rule, err := newPathRule(path)
+		if err != nil {
+			slog.Warn("invalid pinned path", "path", path, "error", err)
+			continue
+		}
+		p.pinned = append(p.pinned, rule)
+	}
+	return p
+}
+
+// isPinned reports whether file must never be evicted
+func (p retentionPolicy) isPinned(file *trash.File) bool {
+	path := file.GetOriginalPath()
+	return slices.ContainsFunc(p.pinned, func(rule protectRule) bool {
+		return rule.match(path)
+	})
+}
+
+// evict returns the files to remove to meet the policy, oldest first.
+// Pinned files and files kept forever by a rule are never returned, nor
+// counted toward the limits, which would otherwise be met by evicting the
+// files just trashed. A rule's retention replaces the max age. size is
+// only called if the total size is limited, as sizing directories is
+// expensive.
+func (p retentionPolicy) evict(files []*trash.File, size func(*trash.File) int64, now time.Time) []*trash.File {
+	sorted := slices.Clone(files)
+	slices.SortStableFunc(sorted, func(a, b *trash.File) int {
+		return a.DeletedAt.Compare(b.DeletedAt)
+	})
+
+	// The max age of each file that can be evicted
+	maxAges := make(map[*trash.File]time.Duration, len(files))
+	for _, f := range sorted {
+		if p.isPinned(f) {
+			continue
+		}
+		maxAge := p.maxAge
+		if rule := p.rules.match(f.GetOriginalPath()); rule != nil {
+			if rule.keep {
+				continue
+			}
+			if rule.maxAge > 0 {
+				maxAge = rule.maxAge
+			}
+		}
+		maxAges[f] = maxAge
+	}
+
+	count := len(maxAges)
+	var (
+		total int64
+		sizes map[*trash.File]int64
+	)
+	if p.maxSize > 0 {
+		sizes = make(map[*trash.File]int64, len(maxAges))
+		for f := range maxAges {
+			sizes[f] = size(f)
+			total += sizes[f]
+		}
+	}
+
+	var evicted []*trash.File
+	for _, f := range sorted {
+		maxAge, ok := maxAges[f]
+		if !ok {
+			continue
+		}
+		tooOld := maxAge > 0 && now.Sub(f.DeletedAt) > maxAge
+		tooMany := p.maxItems > 0 && count > p.maxItems
+		tooLarge := p.maxSize > 0 && total > p.maxSize
+		if !tooOld && !tooMany && !tooLarge {
+			// Newer files may still be older than a rule allows
+			continue
+		}
+		evicted = append(evicted, f)
+		count--
+		total -= sizes[f]
+	}
+	return evicted
+}
+
+// EnforceRetention permanently deletes the oldest files beyond the limits
+// of core.trash.retention, across all trash directories
+func (c *CLI) EnforceRetention() error {
+	slog.Debug("enforcing retention policy started")
+	defer slog.Debug("enforcing retention policy finished")
+
+	if !retentionEnabled(c.config) {
+		return ErrNoRetention
+	}
+	return c.enforceRetention(true)
+}
+
+// autoEnforceRetention enforces the retention policy at the end of Put,
+// at most once per check_interval. Failures are logged, as files were
+// moved to trash anyway.
+func (c *CLI) autoEnforceRetention() {
+	cfg := c.config.Core.Trash.Retention
+	stamp := env.GOMI_RETENTION_PATH
+	if !retentionEnabled(c.config) || c.option.DryRun || stamp == "" {
+		return
+	}
+
+	now := time.Now()
+	if !retentionDue(stamp, newRetentionPolicy(cfg, nil).interval, now) {
+		return
+	}
+	// Recorded first, so that a failing policy is not retried on every put
+	if err := markRetention(stamp, now); err != nil {
+		slog.Warn("failed to record retention run", "path", stamp, "error", err)
+	}
+	if err := c.enforceRetention(false); err != nil {
+		slog.Error("failed to enforce retention policy", "error", err)
+		fmt.Fprintf(os.Stderr, "%s: retention: %v\n", c.version.AppName, err)
+	}
+}
+
+// enforceRetention removes the files evicted by the policy. The files are
+// listed when explicit (--enforce-retention) or with -v.
+func (c *CLI) enforceRetention(explicit bool) error {
+	policy := newRetentionPolicy(c.config.Core.Trash.Retention, c.pathRules())
+
+	files, err := c.trash.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash contents: %w", err)
+	}
+	evicted := policy.evict(files, func(f *trash.File) int64 {
+		return newListEntry(f).Size
+	}, time.Now())
+
+	quiet := c.report != nil || (!explicit && !c.option.Rm.Verbose)
+	if len(evicted) == 0 {
+		if explicit && c.report == nil {
+			fmt.Println("The trash is within the retention limits.")
+		}
+		return nil
+	}
+
+	if c.option.DryRun {
+		for _, file := range evicted {
+			c.plan(pruneRecord(file, outcomePlanned), "permanently delete %s (deleted %s from %s)",
+				file.TrashPath, file.DeletedAt.Format(table.TimeFormat), file.GetOriginalPath())
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, file := range evicted {
+		if err := c.trash.Remove(file); err != nil {
+			slog.Error("failed to evict file", "file", file.TrashPath, "error", err)
+			errs = append(errs, err)
+			c.report.add(pruneRecord(file, outcomeFailed), err)
+			continue
+		}
+		slog.Info("evicted by retention policy",
+			"original_path", file.GetOriginalPath(),
+			"trash_path", file.TrashPath,
+			"deleted_at", file.DeletedAt)
+		c.report.add(pruneRecord(file, outcomeDeleted), nil)
+		if !quiet {
+			fmt.Printf("evicted from trash: %s (deleted %s)\n",
+				file.GetOriginalPath(), file.DeletedAt.Format(table.TimeFormat))
+		}
+	}
+
+	if err := newBatchError(errs); err != nil {
+		return fmt.Errorf("some files could not be removed: %w", err)
+	}
+	if explicit && c.report == nil {
+		fmt.Printf("Removed %d files to meet the retention policy.\n", len(evicted))
+	}
+	return nil
+}
+
+// retentionDue reports whether the policy was last enforced, as recorded
+// in stamp, at least interval ago
+func retentionDue(stamp string, interval time.Duration, now time.Time) bool {
+	data, err := os.ReadFile(stamp)
+	if err != nil {
+		return true
+	}
+	last, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return true
+	}
+	return now.Sub(last) >= interval
+}
+
+// markRetention records in stamp that the policy was enforced at now
+func markRetention(stamp string, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(stamp), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(stamp, []byte(now.Format(time.RFC3339)+"\n"), 0644)
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// retentionFiles returns files trashed 1 to 5 days before now, each
+// 400 bytes, with IDs day1 to day5
+func retentionFiles(now time.Time) []*trash.File {
+	var files []*trash.File
+	for _, day := range []int{3, 1, 5, 2, 4} {
+		id := fmt.Sprintf("day%d", day)
+		files = append(files, &trash.File{
+			ID:           id,
+			Name:         id,
+			OriginalPath: "/home/user/" + id,
+			TrashPath:    "/trash/files/" + id,
+			DeletedAt:    now.Add(-time.Duration(day) * 24 * time.Hour),
+			Size:         400,
+		})
+	}
+	return files
+}
+
+func TestRetentionPolicy_Evict(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cfg  config.RetentionConfig
+		want []string
+	}{
+		{"no limits", config.RetentionConfig{}, nil},
+		{"max age", config.RetentionConfig{MaxAge: "3d"}, []string{"day5", "day4"}},
+		{"max items", config.RetentionConfig{MaxItems: 2}, []string{"day5", "day4", "day3"}},
+		{"max total size", config.RetentionConfig{MaxTotalSize: "1KB"}, []string{"day5", "day4", "day3"}},
+		{"within limits", config.RetentionConfig{MaxAge: "1w", MaxItems: 5}, nil},
+		{"strictest limit wins", config.RetentionConfig{MaxAge: "4d", MaxItems: 3}, []string{"day5", "day4"}},
+		{"pinned", config.RetentionConfig{MaxItems: 2, Pinned: []string{"/home/user/day5", "/home/*/day3"}}, []string{"day4"}},
+		{"pinned beyond the limits", config.RetentionConfig{MaxItems: 2, Pinned: []string{"/home/user/day[345]"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newRetentionPolicy(tt.cfg, nil)
+			var got []string
+			for _, f := range policy.evict(retentionFiles(now), func(f *trash.File) int64 { return f.Size }, now) {
+				got = append(got, f.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("evict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnforceRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RetentionConfig
+		dryRun  bool
+		want    []string
+		wantErr error
+	}{
+		{"enforced", config.RetentionConfig{MaxItems: 3}, false, []string{"day5", "day4"}, nil},
+		{"dry run", config.RetentionConfig{MaxItems: 3}, true, nil, nil},
+		{"no policy", config.RetentionConfig{}, false, nil, ErrNoRetention},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Core.Trash.Retention = tt.cfg
+			ft := &fakeTrash{files: retentionFiles(time.Now())}
+			cli := CLI{config: cfg, trash: ft}
+			cli.option.DryRun = tt.dryRun
+
+			if err := cli.EnforceRetention(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnforceRetention() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ft.removed, tt.want) {
+				t.Errorf("removed %v, want %v", ft.removed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetentionDue(t *testing.T) {
+	stamp := filepath.Join(t.TempDir(), "gomi", "retention")
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	if !retentionDue(stamp, time.Hour, now) {
+		t.Error("retention should be due without a stamp")
+	}
+	if err := markRetention(stamp, now); err != nil {
+		t.Fatal(err)
+	}
+	if retentionDue(stamp, time.Hour, now.Add(30*time.Minute)) {
+		t.Error("retention should not be due within the interval")
+	}
+	if !retentionDue(stamp, time.Hour, now.Add(time.Hour)) {
+		t.Error("retention should be due after the interval")
+	}
+}
+
+func TestOption_RunsPut(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		want bool
+	}{
+		{"put", Option{}, true},
+		{"put with flags", Option{DryRun: true, Rm: RmOption{Force: true}}, true},
+		{"restore", Option{Restore: true}, false},
+		{"enforce retention", Option{Retention: true}, false},
+		{"prune", Option{Meta: MetaOption{Prune: PruneArgs{"30d"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opt.runsPut(); got != tt.want {
+				t.Errorf("runsPut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
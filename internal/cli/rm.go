@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/babarot/gomi/internal/utils/fs"
+)
+
+// rmCompatStrict makes the rm options behave as in GNU rm
+const rmCompatStrict = "strict"
+
+// interactiveMode tells when to prompt before removing files
+type interactiveMode int
+
+const (
+	interactiveNever interactiveMode = iota
+	interactiveOnce
+	interactiveAlways
+)
+
+// bindFlags sets the functions of -f, -i, -I and --interactive. Each of
+// them clears what the others set, so that the last one wins as in rm.
+// Like rm, prompting also stops -f from ignoring nonexistent files.
+func (o *RmOption) bindFlags() {
+	set := func(force, always, once bool, when string) {
+		o.Force, o.Interactive, o.InteractiveOnce, o.InteractiveWhen = force, always, once, when
+	}
+	o.ForceFlag = func() { set(true, false, false, "") }
+	o.InteractiveFlag = func() { set(false, true, false, "") }
+	o.InteractiveOnceFlag = func() { set(false, false, true, "") }
+	o.InteractiveWhenFlag = func(when string) {
+		switch when {
+		case "once", "always", "yes":
+			set(false, false, false, when)
+		default: // never keeps -f
+			set(o.Force, false, false, when)
+		}
+	}
+}
+
+// interactive returns the prompt mode selected by -f, -i, -I and
+// --interactive. On the command line, only the last of them is set; for
+// options set otherwise, -f wins over --interactive, which wins over -i
+// and -I.
+func (o RmOption) interactive() interactiveMode {
+	switch {
+	case o.Force:
+		return interactiveNever
+	case o.InteractiveWhen != "":
+		switch o.InteractiveWhen {
+		case "once":
+			return interactiveOnce
+		case "always", "yes":
+			return interactiveAlways
+		default:
+			return interactiveNever
+		}
+	case o.Interactive:
+		return interactiveAlways
+	case o.InteractiveOnce:
+		return interactiveOnce
+	}
+	return interactiveNever
+}
+
+func (o RmOption) recursive() bool {
+	return o.Recursive || o.Recursive2
+}
+
+// strictRm reports whether rm options behave as in GNU rm
+func (c *CLI) strictRm() bool {
+	return c.config.Core.RmCompat == rmCompatStrict
+}
+
+// putStrict moves files to trash one by one in the given order, as rm
+// does, so that prompts follow the arguments and a failure on one file
+// does not stop the others
+func (c *CLI) putStrict(files iter.Seq2[string, error]) error {
+	mode := c.option.Rm.interactive()
+	if mode != interactiveNever && !c.option.DryRun && c.option.FilesFrom == "-" {
+		return ErrPromptFromStdin
+	}
+
+	if mode == interactiveOnce && !c.option.DryRun {
+		// -I needs the number of files before removing any of them
+		args, err := collectNames(files)
+		if err != nil {
+			return err
+		}
+		if !c.confirmOnce(args) {
+			return nil
+		}
+		files = seqOf(args)
+	}
+
+	failed := &syncSlice[string]{}
+	for arg, err := range files {
+		if err != nil {
+			return err
+		}
+		if err := c.processFile(arg, failed); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", c.version.AppName, err)
+		}
+	}
+
+	// Each failure was printed above
+	if len(failed.Get()) > 0 {
+		return ErrReported
+	}
+	return nil
+}
+
+// confirmOnce asks once, for -I, before removing more than three files
+// or removing recursively
+func (c *CLI) confirmOnce(args []string) bool {
+	recursive := c.option.Rm.recursive()
+	if len(args) <= 3 && !recursive {
+		return true
+	}
+
+	noun := "arguments"
+	if len(args) == 1 {
+		noun = "argument"
+	}
+	prompt := fmt.Sprintf("remove %d %s", len(args), noun)
+	if recursive {
+		prompt += " recursively"
+	}
+	return c.prompter.Confirm(prompt + "?")
+}
+
+// checkRm applies the rules of rm to path before it is moved to trash.
+// It returns false if the user declined to remove it.
+func (c *CLI) checkRm(arg, path string, info os.FileInfo) (bool, error) {
+	opt := c.option.Rm
+
+	if info.IsDir() {
+		switch {
+		case opt.recursive():
+			if err := c.checkDevices(arg, path); err != nil {
+				return false, err
+			}
+		case opt.Directory:
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return false, fmt.Errorf("cannot remove '%s': %w", arg, err)
+			}
+			if len(entries) > 0 {
+				return false, fmt.Errorf("cannot remove '%s': Directory not empty", arg)
+			}
+		default:
+			return false, fmt.Errorf("cannot remove '%s': Is a directory", arg)
+		}
+	}
+
+	if opt.interactive() == interactiveAlways && !c.option.DryRun {
+		return c.prompter.Confirm(fmt.Sprintf("remove %s '%s'?", fileKind(info), arg)), nil
+	}
+	return true, nil
+}
+
+// checkDevices enforces --preserve-root=all and --one-file-system for a
+// directory removed recursively
+func (c *CLI) checkDevices(arg, path string) error {
+	if c.option.Rm.PreserveRoot == "all" {
+		same, err := fs.SameDevice(path, filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("cannot remove '%s': %w", arg, err)
+		}
+		if !same {
+			return fmt.Errorf("skipping '%s', since it's on a different device and --preserve-root=all is in effect", arg)
+		}
+	}
+
+	if c.option.Rm.OneFileSystem {
+		// A directory is trashed as a whole, so a mount point below it
+		// makes the whole argument fail rather than being skipped
+		var other string
+		err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() || p == path {
+				return nil
+			}
+			same, err := fs.SameDevice(path, p)
+			if err != nil {
+				return err
+			}
+			if !same {
+				other = p
+				return filepath.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot remove '%s': %w", arg, err)
+		}
+		if other != "" {
+			return fmt.Errorf("skipping '%s', since '%s' is on a different device", arg, other)
+		}
+	}
+
+	return nil
+}
+
+// fileKind describes a file the way rm does in its prompts
+func fileKind(info os.FileInfo) string {
+	mode := info.Mode()
+	switch {
+	case mode.IsDir():
+		return "directory"
+	case mode&os.ModeSymlink != 0:
+		return "symbolic link"
+	case mode.IsRegular() && info.Size() == 0:
+		return "regular empty file"
+	case mode.IsRegular():
+		return "regular file"
+	case mode&os.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	default:
+		return "file"
+	}
+}
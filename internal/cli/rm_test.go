@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// fakePrompter answers every confirmation with answer and records the prompts
+type fakePrompter struct {
+	answer  bool
+	prompts []string
+}
+
+func (p *fakePrompter) Confirm(prompt string) bool {
+	p.prompts = append(p.prompts, prompt)
+	return p.answer
+}
+
+func (p *fakePrompter) ConfirmYes(prompt string) bool { return p.Confirm(prompt) }
+
+func (p *fakePrompter) InputFilename(*trash.File) (string, error) { return "", nil }
+func (p *fakePrompter) InputDirectory() (string, error)           { return "", nil }
+
+func newStrictCLI(opt RmOption, answer bool) (*CLI, *fakeTrash, *fakePrompter) {
+	cfg := config.NewDefaultConfig()
+	cfg.Core.RmCompat = rmCompatStrict
+	ft := &fakeTrash{}
+	p := &fakePrompter{answer: answer}
+	cli := &CLI{config: cfg, trash: ft, prompter: p}
+	cli.option.Rm = opt
+	return cli, ft, p
+}
+
+func TestRmOption_Interactive(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  RmOption
+		want interactiveMode
+	}{
+		{"default", RmOption{}, interactiveNever},
+		{"-i", RmOption{Interactive: true}, interactiveAlways},
+		{"-I", RmOption{InteractiveOnce: true}, interactiveOnce},
+		{"--interactive", RmOption{InteractiveWhen: "always"}, interactiveAlways},
+		{"--interactive=once", RmOption{InteractiveWhen: "once"}, interactiveOnce},
+		{"--interactive=never overrides -i", RmOption{Interactive: true, InteractiveWhen: "never"}, interactiveNever},
+		{"-f overrides -i", RmOption{Interactive: true, Force: true}, interactiveNever},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opt.interactive(); got != tt.want {
+				t.Errorf("interactive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRmOption_LastFlagWins(t *testing.T) {
+	tests := []struct {
+		args      []string
+		want      interactiveMode
+		wantForce bool
+	}{
+		{[]string{"-fi"}, interactiveAlways, false},
+		{[]string{"-if"}, interactiveNever, true},
+		{[]string{"-i", "-I"}, interactiveOnce, false},
+		{[]string{"-I", "--interactive=always"}, interactiveAlways, false},
+		{[]string{"--interactive", "-f"}, interactiveNever, true},
+		{[]string{"-f", "--interactive=never"}, interactiveNever, true},
+		{[]string{"-i", "--interactive=never"}, interactiveNever, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[0]+" "+tt.args[len(tt.args)-1], func(t *testing.T) {
+			var opt RmOption
+			opt.bindFlags()
+			if _, err := flags.ParseArgs(&opt, tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := opt.interactive(); got != tt.want {
+				t.Errorf("interactive() = %v, want %v", got, tt.want)
+			}
+			if opt.Force != tt.wantForce {
+				t.Errorf("Force = %v, want %v", opt.Force, tt.wantForce)
+			}
+		})
+	}
+}
+
+func TestPut_StrictReportsEachFailureOnce(t *testing.T) {
+	cli, _, _ := newStrictCLI(RmOption{}, true)
+	err := cli.Put([]string{filepath.Join(t.TempDir(), "none")})
+	if !errors.Is(err, ErrReported) {
+		t.Fatalf("Put() error = %v, want %v", err, ErrReported)
+	}
+	if code := ExitCode(err); code != exitFailure {
+		t.Errorf("ExitCode() = %d, want %d as in rm", code, exitFailure)
+	}
+}
+
+func TestPut_Strict(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	empty := filepath.Join(dir, "empty")
+	full := filepath.Join(dir, "full")
+	for _, d := range []string{empty, full} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{file, filepath.Join(full, "a")} {
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opt     RmOption
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"file", RmOption{}, []string{file}, []string{file}, false},
+		{"directory without -r", RmOption{}, []string{empty, file}, []string{file}, true},
+		{"-d removes empty directory", RmOption{Directory: true}, []string{empty}, []string{empty}, false},
+		{"-d refuses non-empty directory", RmOption{Directory: true}, []string{full}, nil, true},
+		{"-r", RmOption{Recursive: true}, []string{full}, []string{full}, false},
+		{"-R", RmOption{Recursive2: true}, []string{full}, []string{full}, false},
+		{"--one-file-system", RmOption{Recursive: true, OneFileSystem: true}, []string{full}, []string{full}, false},
+		{"missing file", RmOption{}, []string{filepath.Join(dir, "none")}, nil, true},
+		{"missing file with -f", RmOption{Force: true}, []string{filepath.Join(dir, "none")}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, ft, _ := newStrictCLI(tt.opt, true)
+			err := cli.Put(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(ft.put) != len(tt.want) {
+				t.Fatalf("put %v, want %v", ft.put, tt.want)
+			}
+			for i := range tt.want {
+				if ft.put[i] != tt.want[i] {
+					t.Errorf("put[%d] = %q, want %q", i, ft.put[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPut_StrictPrompts(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	tests := []struct {
+		name        string
+		opt         RmOption
+		args        []string
+		answer      bool
+		wantPrompts int
+		wantPut     int
+	}{
+		{"-i prompts per file", RmOption{Interactive: true}, files[:2], true, 2, 2},
+		{"-i declined", RmOption{Interactive: true}, files[:2], false, 2, 0},
+		{"-I with three files", RmOption{InteractiveOnce: true}, files[:3], false, 0, 3},
+		{"-I with four files", RmOption{InteractiveOnce: true}, files, true, 1, 4},
+		{"-I declined", RmOption{InteractiveOnce: true}, files, false, 1, 0},
+		{"-I recursive", RmOption{InteractiveOnce: true, Recursive: true}, files[:1], false, 1, 0},
+		{"-f never prompts", RmOption{Interactive: true, Force: true}, files, false, 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, ft, p := newStrictCLI(tt.opt, tt.answer)
+			if err := cli.Put(tt.args); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if len(p.prompts) != tt.wantPrompts {
+				t.Errorf("prompts = %q, want %d", p.prompts, tt.wantPrompts)
+			}
+			if len(ft.put) != tt.wantPut {
+				t.Errorf("put %d files, want %d", len(ft.put), tt.wantPut)
+			}
+		})
+	}
+}
+
+func TestPut_LooseIgnoresRmOptions(t *testing.T) {
+	dir := t.TempDir()
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}}
+	cli.option.Rm.Interactive = true
+	if err := cli.Put([]string{dir}); err != nil {
+		t.Errorf("Put() error = %v, directories should be trashed without -r", err)
+	}
+}
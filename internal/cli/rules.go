@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/utils/duration"
+	"github.com/babarot/gomi/internal/utils/shell"
+)
+
+// Actions of core.rules entries
+const (
+	ruleTrash  = "trash"
+	ruleDelete = "delete"
+	ruleRefuse = "refuse"
+)
+
+// retentionNever keeps the files of a rule forever
+const retentionNever = "never"
+
+// ErrRoutingUnsupported is returned when a rule sets a storage that the
+// trash cannot route files to
+var ErrRoutingUnsupported = errors.New("moving files to a given storage is not supported")
+
+// putRule is a compiled core.rules entry
+type putRule struct {
+	protectRule
+
+	action    string
+	storage   string
+	retention string
+
+	// maxAge overrides core.trash.retention.max_age, and keep exempts the
+	// files from the retention policy altogether
+	maxAge time.Duration
+	keep   bool
+}
+
+// ruleSet holds core.rules in order
+type ruleSet struct {
+	rules []*putRule
+}
+
+// newRuleSet compiles the rules. Rules that cannot be compiled are
+// skipped; the config validation reports them.
+func newRuleSet(rules []config.Rule) *ruleSet {
+	s := &ruleSet{}
+	for _, r := range rules {
+		var pathRule protectRule
+		pattern, err := rulePattern(r.Path)
+		if err == nil {
+			pathRule, err = newPathRule(pattern)
+		}
+		if err != nil {
+			slog.Warn("invalid rule path", "path", r.Path, "error", err)
+			continue
+		}
+		pathRule.entry = r.Path
+		rule := &putRule{
+			protectRule: pathRule,
+			action:      r.Action,
+			storage:     r.Storage,
+			retention:   r.Retention,
+		}
+		if rule.action == "" {
+			rule.action = ruleTrash
+		}
+		switch r.Retention {
+		case "":
+		case retentionNever:
+			rule.keep = true
+		default:
+			rule.maxAge, _ = duration.Parse(r.Retention)
+		}
+		s.rules = append(s.rules, rule)
+	}
+	return s
+}
+
+// rulePattern returns the pattern matched by the path of a rule. Paths
+// are matched as absolute paths, so a relative path such as "target/"
+// matches that name at any depth, like "**/target".
+func rulePattern(entry string) (string, error) {
+	path, err := shell.ExpandHome(entry)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	return "**" + string(filepath.Separator) + filepath.Clean(path), nil
+}
+
+// match returns the first rule matching the absolute path, or with the
+// symlinks of its parent directories resolved, or nil
+func (s *ruleSet) match(path string) *putRule {
+	if s == nil || len(s.rules) == 0 {
+		return nil
+	}
+	candidates := []string{path}
+	if resolved := resolveParent(path); resolved != path {
+		candidates = append(candidates, resolved)
+	}
+	for _, rule := range s.rules {
+		if slices.ContainsFunc(candidates, rule.match) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// String describes the rule for verbose and dry-run output
+func (r *putRule) String() string {
+	s := "rule " + r.entry
+	switch {
+	case r.keep:
+		s += ", kept forever"
+	case r.maxAge > 0:
+		s += ", kept " + r.retention
+	}
+	if r.storage != "" {
+		s += ", " + r.storage + " storage"
+	}
+	return s
+}
+
+// pathRules returns the rules built from the config. Put builds them
+// before starting its workers, which then share them.
+func (c *CLI) pathRules() *ruleSet {
+	if c.rules == nil {
+		c.rules = newRuleSet(c.config.Core.Rules)
+	}
+	return c.rules
+}
+
+// putFileByRule moves path to trash, in the storage set by rule if any
+func (c *CLI) putFileByRule(path string, rule *putRule) (*trash.File, error) {
+	if rule == nil || rule.storage == "" {
+		return c.putFile(path)
+	}
+	router, ok := c.trash.(trash.Router)
+	if !ok {
+		return nil, ErrRoutingUnsupported
+	}
+	return router.PutFileTo(path, storageType(rule.storage))
+}
+
+// deleteByRule permanently deletes path as a delete rule says, instead of
+// moving it to trash
+func (c *CLI) deleteByRule(arg, path string, info os.FileInfo, rule *putRule, rec reportRecord, fail func(error) error) error {
+	if c.option.DryRun {
+		rec.Outcome = outcomePlanned
+		c.plan(rec, "permanently delete %s%s", arg, ruleNote(rule))
+		return nil
+	}
+
+	// The trash runs the hooks around the deletion
+	remove := os.RemoveAll
+	if deleter, ok := c.trash.(trash.Deleter); ok {
+		remove = deleter.Delete
+	}
+	c.progress.start(path)
+	if err := remove(path); err != nil {
+		return fail(fmt.Errorf("failed to delete permanently: %w", err))
+	}
+	slog.Info("deleted permanently by rule", "path", path, "rule", rule.entry)
+
+	var size int64
+	if info != nil && info.Mode().IsRegular() {
+		size = info.Size()
+	}
+	c.progress.add(path, size)
+
+	rec.Outcome = outcomeDeleted
+	c.report.add(rec, nil)
+
+	if c.option.Rm.Verbose && c.report == nil {
+		fmt.Printf("deleted permanently: %s%s\n", path, ruleNote(rule))
+	}
+	return nil
+}
+
+// storageType returns the storage type named by core.rules
+func storageType(name string) trash.StorageType {
+	if name == trash.StorageTypeLegacy.String() {
+		return trash.StorageTypeLegacy
+	}
+	return trash.StorageTypeXDG
+}
+
+// ruleNote returns the note appended to output about rule, if any
+func ruleNote(rule *putRule) string {
+	if rule == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", rule)
+}
+
+// retentionEnabled reports whether the retention policy can evict
+// anything: a limit of core.trash.retention, or a rule with a max age
+func retentionEnabled(cfg *config.Config) bool {
+	return cfg.Core.Trash.Retention.Enabled() || slices.ContainsFunc(cfg.Core.Rules, func(r config.Rule) bool {
+		return r.Retention != "" && r.Retention != retentionNever
+	})
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func TestRuleSet_Match(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	rules := newRuleSet([]config.Rule{
+		{Path: "~/src/**/node_modules", Action: ruleDelete},
+		{Path: "~/Documents", Retention: "never"},
+		{Path: "~/Downloads", Retention: "7d", Storage: "legacy"},
+		{Path: "target/", Action: ruleDelete},
+		{Path: "~/*", Action: ruleRefuse},
+	})
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"glob", filepath.Join(home, "src/app/web/node_modules"), "~/src/**/node_modules"},
+		{"under glob", filepath.Join(home, "src/app/node_modules/react/index.js"), "~/src/**/node_modules"},
+		{"directory", filepath.Join(home, "Documents/report.pdf"), "~/Documents"},
+		{"first match wins", filepath.Join(home, "Downloads"), "~/Downloads"},
+		{"relative", filepath.Join(home, "src/app/target"), "target/"},
+		{"under relative", filepath.Join(home, "src/app/target/debug/app"), "target/"},
+		{"relative name only", filepath.Join(home, "src/app/target.txt"), "~/*"},
+		{"last rule", filepath.Join(home, "notes.txt"), "~/*"},
+		{"no match", "/tmp/a.txt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if rule := rules.match(tt.path); rule != nil {
+				got = rule.entry
+			}
+			if got != tt.want {
+				t.Errorf("match(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	var none *ruleSet
+	if none.match("/tmp/a.txt") != nil {
+		t.Error("a nil rule set should match nothing")
+	}
+}
+
+func TestPut_Rules(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	secret := filepath.Join(dir, "secret.key")
+	notes := filepath.Join(dir, "notes.txt")
+	for _, path := range []string{cache, secret, notes} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Core.Rules = []config.Rule{
+		{Path: cache, Action: ruleDelete},
+		{Path: filepath.Join(dir, "*.key"), Action: ruleRefuse},
+		{Path: dir, Retention: "7d"},
+	}
+	var buf bytes.Buffer
+	ft := &fakeTrash{}
+	cli := CLI{config: cfg, trash: ft, report: newReporter(&buf)}
+
+	err := cli.Put([]string{cache, secret, notes})
+	if !errors.Is(err, ErrForbiddenPath) {
+		t.Fatalf("Put() error = %v, want %v", err, ErrForbiddenPath)
+	}
+	if _, err := os.Lstat(cache); !os.IsNotExist(err) {
+		t.Errorf("%s should be deleted permanently", cache)
+	}
+	if !slices.Equal(ft.put, []string{notes}) {
+		t.Errorf("put %v, want %v", ft.put, []string{notes})
+	}
+
+	outcomes := map[string]string{}
+	for _, rec := range readRecords(t, &buf) {
+		outcomes[rec.Path] = rec.Outcome + " " + rec.Rule
+	}
+	want := map[string]string{
+		cache:  outcomeDeleted + " " + cache,
+		secret: outcomeForbidden + " " + filepath.Join(dir, "*.key"),
+		notes:  outcomeTrashed + " " + dir,
+	}
+	for path, w := range want {
+		if outcomes[path] != w {
+			t.Errorf("record of %s = %q, want %q", path, outcomes[path], w)
+		}
+	}
+}
+
+// vetoTrash vetoes every permanent deletion
+type vetoTrash struct {
+	*fakeTrash
+}
+
+func (vetoTrash) Delete(string) error { return trash.ErrVetoed }
+
+func TestPut_RuleDeleteVetoed(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(cache, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Core.Rules = []config.Rule{{Path: cache, Action: ruleDelete}}
+	cli := CLI{config: cfg, trash: vetoTrash{&fakeTrash{}}}
+
+	if err := cli.Put([]string{cache}); !errors.Is(err, trash.ErrVetoed) {
+		t.Errorf("Put() error = %v, want %v", err, trash.ErrVetoed)
+	}
+	if _, err := os.Lstat(cache); err != nil {
+		t.Errorf("%s should be kept when the hook vetoes", cache)
+	}
+}
+
+func TestPut_RulesDryRun(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	if err := os.WriteFile(cache, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Core.Rules = []config.Rule{{Path: cache, Action: ruleDelete}}
+	var buf bytes.Buffer
+	cli := CLI{config: cfg, trash: &fakeTrash{}, report: newReporter(&buf)}
+	cli.option.DryRun = true
+
+	if err := cli.Put([]string{cache}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Lstat(cache); err != nil {
+		t.Errorf("--dry-run should not delete %s", cache)
+	}
+	want := []reportRecord{{Op: "put", Arg: cache, Path: cache, Outcome: outcomePlanned, Rule: cache}}
+	if got := readRecords(t, &buf); !slices.Equal(got, want) {
+		t.Errorf("records = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestPut_RuleStorageUnsupported(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Core.Rules = []config.Rule{{Path: file, Storage: "legacy"}}
+	cli := CLI{config: cfg, trash: &fakeTrash{}}
+
+	if err := cli.Put([]string{file}); !errors.Is(err, ErrRoutingUnsupported) {
+		t.Errorf("Put() error = %v, want %v", err, ErrRoutingUnsupported)
+	}
+}
+
+func TestRetentionPolicy_EvictRules(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	rules := newRuleSet([]config.Rule{
+		{Path: "/home/user/day5", Retention: "never"},
+		{Path: "/home/user/day2", Retention: "1d"},
+	})
+
+	tests := []struct {
+		name string
+		cfg  config.RetentionConfig
+		want []string
+	}{
+		{"rule max age", config.RetentionConfig{}, []string{"day2"}},
+		{"kept forever", config.RetentionConfig{MaxAge: "3d"}, []string{"day4", "day2"}},
+		{"kept forever not counted toward limits", config.RetentionConfig{MaxItems: 3}, []string{"day4", "day2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newRetentionPolicy(tt.cfg, rules)
+			var got []string
+			for _, f := range policy.evict(retentionFiles(now), func(f *trash.File) int64 { return f.Size }, now) {
+				got = append(got, f.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("evict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetentionEnabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []config.Rule
+		want  bool
+	}{
+		{"no rules", nil, false},
+		{"kept forever", []config.Rule{{Path: "~/Documents", Retention: "never"}}, false},
+		{"rule max age", []config.Rule{{Path: "~/Downloads", Retention: "7d"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Core.Rules = tt.rules
+			if got := retentionEnabled(cfg); got != tt.want {
+				t.Errorf("retentionEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/ui/table"
+)
+
+// statsTop is the number of directories and items in the top lists
+const statsTop = 10
+
+// ErrStatsFormat is returned for --format values that --stats cannot print
+var ErrStatsFormat = errors.New("stats can only be printed as table or json")
+
+// statsAges are the buckets of the deletion age histogram. The last one
+// has no upper bound.
+var statsAges = []struct {
+	name string
+	max  time.Duration
+}{
+	{"< 1 day", 24 * time.Hour},
+	{"1-7 days", 7 * 24 * time.Hour},
+	{"7-30 days", 30 * 24 * time.Hour},
+	{"30-90 days", 90 * 24 * time.Hour},
+	{"90-365 days", 365 * 24 * time.Hour},
+	{"> 1 year", 0},
+}
+
+// statsGroup is the number and total size of a group of trashed files
+type statsGroup struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+func (g *statsGroup) add(size int64) {
+	g.Count++
+	g.Size += size
+}
+
+// trashStats is the report printed by --stats
+type trashStats struct {
+	Total       statsGroup   `json:"total"`
+	Backends    []statsGroup `json:"backends"`
+	TrashDirs   []statsGroup `json:"trash_dirs"`
+	Ages        []statsGroup `json:"ages"`
+	Directories []statsGroup `json:"top_directories"`
+	Largest     []listEntry  `json:"largest"`
+
+	// Reclaimable is what --prune with the given arguments would free
+	Reclaimable *statsGroup `json:"reclaimable,omitempty"`
+}
+
+// Stats prints how much space the trash uses, broken down by backend,
+// trash directory, deletion age and original directory. With --prune,
+// it also prints how much pruning would reclaim, without deleting anything.
+func (c *CLI) Stats() error {
+	slog.Debug("collecting trash stats", "format", c.option.Output.Format)
+
+	format := c.option.Output.Format
+	if format != "table" && format != "json" {
+		return fmt.Errorf("stats: %w", ErrStatsFormat)
+	}
+
+	var sel *pruneSelector
+	if len(c.option.Meta.Prune) > 0 {
+		var err error
+		if sel, err = parsePruneArgs(c.option.Meta.Prune); err != nil {
+			return fmt.Errorf("stats: %w", err)
+		}
+	}
+
+	files, err := c.trash.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash contents: %w", err)
+	}
+
+	var bins []trash.Bin
+	if emptier, ok := c.trash.(trash.Emptier); ok {
+		bins = emptier.Bins(files)
+	}
+
+	st := collectStats(files, bins, sel, time.Now())
+	if sel != nil {
+		st.Reclaimable.Name = "--prune=" + strings.Join(c.option.Meta.Prune, ",")
+	}
+	return writeStats(os.Stdout, st, format)
+}
+
+// collectStats builds the report for files, grouped into bins by trash
+// directory. If sel is not nil, the files it selects are counted as
+// reclaimable.
+func collectStats(files []*trash.File, bins []trash.Bin, sel *pruneSelector, now time.Time) *trashStats {
+	st := &trashStats{Total: statsGroup{Name: "total"}}
+
+	sizes := make(map[*trash.File]int64, len(files))
+	entries := make([]listEntry, 0, len(files))
+	backends := make(map[string]*statsGroup)
+	dirs := make(map[string]*statsGroup)
+	ages := make([]statsGroup, len(statsAges))
+	for i, age := range statsAges {
+		ages[i].Name = age.name
+	}
+
+	for _, f := range files {
+		e := newListEntry(f)
+		sizes[f] = e.Size
+		entries = append(entries, e)
+		st.Total.add(e.Size)
+
+		if backends[e.Backend] == nil {
+			backends[e.Backend] = &statsGroup{Name: e.Backend}
+		}
+		backends[e.Backend].add(e.Size)
+
+		dir := filepath.Dir(e.OriginalPath)
+		if dirs[dir] == nil {
+			dirs[dir] = &statsGroup{Name: dir}
+		}
+		dirs[dir].add(e.Size)
+
+		age := now.Sub(e.DeletedAt)
+		for i, bucket := range statsAges {
+			if bucket.max == 0 || age < bucket.max {
+				ages[i].add(e.Size)
+				break
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(backends)) {
+		st.Backends = append(st.Backends, *backends[name])
+	}
+	for _, bin := range bins {
+		g := statsGroup{Name: bin.Dir}
+		for _, f := range bin.Files {
+			g.add(sizes[f])
+		}
+		st.TrashDirs = append(st.TrashDirs, g)
+	}
+	st.Ages = ages
+
+	for _, g := range dirs {
+		st.Directories = append(st.Directories, *g)
+	}
+	slices.SortFunc(st.Directories, func(a, b statsGroup) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+	st.Directories = st.Directories[:min(len(st.Directories), statsTop)]
+
+	sortListEntries(entries, "size", false)
+	st.Largest = entries[:min(len(entries), statsTop)]
+
+	if sel != nil {
+		st.Reclaimable = &statsGroup{}
+		for _, f := range sel.selectFiles(files) {
+			st.Reclaimable.add(sizes[f])
+		}
+	}
+
+	return st
+}
+
+// writeStats writes the report to w as tables or as JSON
+func writeStats(w io.Writer, st *trashStats, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+
+	fmt.Fprintf(w, "%d files in trash (%s)\n", st.Total.Count, humanize.Bytes(uint64(st.Total.Size)))
+	if st.Total.Count == 0 {
+		return nil
+	}
+
+	groups := func(title, column string, groups []statsGroup) {
+		fmt.Fprintf(w, "\n%s\n", title)
+		var rows [][]string
+		for _, g := range groups {
+			rows = append(rows, []string{g.Name, strconv.Itoa(g.Count), humanize.Bytes(uint64(g.Size))})
+		}
+		table.Render(w, []string{column, "Files", "Size"}, rows)
+	}
+	groups("By backend", "Backend", st.Backends)
+	if len(st.TrashDirs) > 0 {
+		groups("By trash directory", "Trash Directory", st.TrashDirs)
+	}
+
+	fmt.Fprintf(w, "\nBy deletion age\n")
+	var rows [][]string
+	for _, g := range st.Ages {
+		rows = append(rows, []string{g.Name, strconv.Itoa(g.Count), humanize.Bytes(uint64(g.Size)), statsBar(g.Size, st.Total.Size)})
+	}
+	table.Render(w, []string{"Age", "Files", "Size", ""}, rows)
+
+	groups("Top original directories", "Directory", st.Directories)
+
+	fmt.Fprintf(w, "\nLargest items\n")
+	rows = nil
+	for _, e := range st.Largest {
+		rows = append(rows, []string{humanize.Bytes(uint64(e.Size)), e.DeletedAt.Format(table.TimeFormat), e.OriginalPath})
+	}
+	table.Render(w, []string{"Size", "Deleted At", "Original Path"}, rows)
+
+	if st.Reclaimable != nil {
+		fmt.Fprintf(w, "\n%s would reclaim %s (%d files)\n",
+			st.Reclaimable.Name, humanize.Bytes(uint64(st.Reclaimable.Size)), st.Reclaimable.Count)
+	}
+	return nil
+}
+
+// statsBar draws size as a share of total, 20 characters wide when full
+func statsBar(size, total int64) string {
+	if total <= 0 {
+		return ""
+	}
+	return strings.Repeat("█", int(size*20/total))
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func testStatsFiles(now time.Time) []*trash.File {
+	day := 24 * time.Hour
+	return []*trash.File{
+		{ID: "a", Name: "a.iso", OriginalPath: "/home/u/dl/a.iso", TrashPath: "/t1/files/a", DeletedAt: now.Add(-time.Hour), Size: 3000, Backend: trash.StorageTypeXDG},
+		{ID: "b", Name: "b.txt", OriginalPath: "/home/u/b.txt", TrashPath: "/t1/files/b", DeletedAt: now.Add(-10 * day), Size: 10, Backend: trash.StorageTypeXDG},
+		{ID: "c", Name: "c.log", OriginalPath: "/home/u/dl/c.log", TrashPath: "/t2/files/c", DeletedAt: now.Add(-40 * day), Size: 500, Backend: trash.StorageTypeXDG},
+		{ID: "d", Name: "d", OriginalPath: "/var/tmp/d", TrashPath: "/g/d", DeletedAt: now.Add(-400 * day), Size: 90, Backend: trash.StorageTypeLegacy},
+	}
+}
+
+func TestCollectStats(t *testing.T) {
+	now := time.Now()
+	files := testStatsFiles(now)
+	bins := []trash.Bin{
+		{Backend: trash.StorageTypeXDG, Dir: "/t1", Files: files[:2]},
+		{Backend: trash.StorageTypeXDG, Dir: "/t2", Files: files[2:3]},
+		{Backend: trash.StorageTypeLegacy, Dir: "/g", Files: files[3:]},
+	}
+	sel, err := parsePruneArgs([]string{"30d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := collectStats(files, bins, sel, now)
+
+	if st.Total.Count != 4 || st.Total.Size != 3600 {
+		t.Errorf("Total = %+v, want 4 files of 3600 bytes", st.Total)
+	}
+	wantBackends := []statsGroup{{"legacy", 1, 90}, {"xdg", 3, 3510}}
+	if len(st.Backends) != 2 || st.Backends[0] != wantBackends[0] || st.Backends[1] != wantBackends[1] {
+		t.Errorf("Backends = %+v, want %+v", st.Backends, wantBackends)
+	}
+	if len(st.TrashDirs) != 3 || st.TrashDirs[0] != (statsGroup{"/t1", 2, 3010}) {
+		t.Errorf("TrashDirs = %+v", st.TrashDirs)
+	}
+	var ageCounts []int
+	for _, g := range st.Ages {
+		ageCounts = append(ageCounts, g.Count)
+	}
+	if want := []int{1, 0, 1, 1, 0, 1}; !slices.Equal(ageCounts, want) {
+		t.Errorf("age counts = %v, want %v", ageCounts, want)
+	}
+	if st.Directories[0] != (statsGroup{"/home/u/dl", 2, 3500}) {
+		t.Errorf("top directory = %+v", st.Directories[0])
+	}
+	if st.Largest[0].ID != "a" || st.Largest[len(st.Largest)-1].ID != "b" {
+		t.Errorf("Largest = %+v", st.Largest)
+	}
+	if st.Reclaimable == nil || *st.Reclaimable != (statsGroup{"", 2, 590}) {
+		t.Errorf("Reclaimable = %+v, want 2 files of 590 bytes", st.Reclaimable)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	now := time.Now()
+	st := collectStats(testStatsFiles(now), nil, nil, now)
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeStats(&buf, st, "json"); err != nil {
+			t.Fatalf("writeStats() error = %v", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if _, ok := got["reclaimable"]; ok {
+			t.Error("reclaimable should be omitted without --prune")
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeStats(&buf, st, "table"); err != nil {
+			t.Fatalf("writeStats() error = %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"4 files in trash (3.6 kB)", "By deletion age", "/home/u/dl/a.iso"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output does not contain %q:\n%s", want, out)
+			}
+		}
+		if strings.Contains(out, "By trash directory") {
+			t.Error("trash directories should be omitted when unknown")
+		}
+	})
+}
+
+func TestStats_Format(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}}
+	cli.option.Output.Format = "csv"
+	if err := cli.Stats(); !errors.Is(err, ErrStatsFormat) {
+		t.Errorf("Stats() error = %v, want %v", err, ErrStatsFormat)
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/ui/table"
+)
+
+// lastRun selects the most recent run for --undo without an argument
+const lastRun = "last"
+
+var (
+	// ErrRunNotFound is returned when no trashed file belongs to the run
+	ErrRunNotFound = errors.New("no files found for this run")
+
+	// ErrUndoConflict is returned when a file of the run cannot be restored
+	// because its original path is taken
+	ErrUndoConflict = errors.New("some original paths already exist, nothing was restored")
+)
+
+// Undo restores every file trashed by one gomi invocation. Either all of
+// them are restored or, if any of them fails, none of them is.
+func (c *CLI) Undo(runID string) error {
+	slog.Debug("undoing run", "run_id", runID)
+
+	files, err := c.trash.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash contents: %w", err)
+	}
+
+	runID, batch := selectRun(c.filterFiles(files), runID)
+	if len(batch) == 0 {
+		return fmt.Errorf("undo: %w", ErrRunNotFound)
+	}
+
+	// Check every destination up front so that nothing is restored on conflict
+	var conflicts []string
+	for _, file := range batch {
+		if _, err := os.Lstat(file.GetOriginalPath()); err == nil {
+			conflicts = append(conflicts, file.GetOriginalPath())
+		}
+	}
+	if len(conflicts) > 0 {
+		fmt.Printf("%d files would overwrite existing files:\n", len(conflicts))
+		for _, path := range conflicts {
+			fmt.Println("-", path)
+		}
+		return fmt.Errorf("undo: %w", ErrUndoConflict)
+	}
+
+	if c.option.DryRun {
+		for _, file := range batch {
+			printPlan("restore %s to %s", file.TrashPath, file.GetOriginalPath())
+		}
+		return nil
+	}
+
+	if !c.option.Rm.Force {
+		table.PrintFiles(batch, table.PrintOptions{
+			ShowRelativeTime: true,
+			Order:            table.SortDesc,
+		})
+		fmt.Println()
+		if !c.prompter.Confirm(fmt.Sprintf("Restore these %d files deleted by run %s?", len(batch), runID)) {
+			fmt.Println("Operation canceled.")
+			return nil
+		}
+	}
+
+	var restored []*trash.File
+	for _, file := range batch {
+		dst := file.GetOriginalPath()
+		slog.Debug("restoring file of run", "file", file.TrashPath, "dst", dst)
+		if err := c.trash.Restore(file, dst); err != nil {
+			c.rollbackUndo(restored)
+			return fmt.Errorf("failed to restore '%s', nothing was restored: %w", file.Name, err)
+		}
+		restored = append(restored, file)
+	}
+
+	fmt.Printf("Successfully restored %d files.\n", len(restored))
+	return nil
+}
+
+// rollbackUndo moves files restored by an incomplete undo back to the
+// trash as they were, keeping their run ID so that the undo can be retried
+func (c *CLI) rollbackUndo(restored []*trash.File) {
+	reverter, _ := c.trash.(trash.Reverter)
+	for _, file := range slices.Backward(restored) {
+		path := file.GetOriginalPath()
+		err := fmt.Errorf("%w: trash cannot revert a restore", errors.ErrUnsupported)
+		if reverter != nil {
+			err = reverter.RevertRestore(file, path)
+		}
+		if err != nil {
+			slog.Error("failed to move restored file back to trash", "path", path, "error", err)
+			fmt.Fprintf(os.Stderr, "could not move %s back to the trash: %v\n", path, err)
+		}
+	}
+}
+
+// selectRun returns the files trashed by runID, or by the most recent run
+// if runID is "last". Files are ordered by original path so that
+// directories are restored before anything inside them.
+func selectRun(files []*trash.File, runID string) (string, []*trash.File) {
+	if runID == lastRun {
+		var latest *trash.File
+		for _, f := range files {
+			if f.RunID != "" && (latest == nil || f.DeletedAt.After(latest.DeletedAt)) {
+				latest = f
+			}
+		}
+		if latest == nil {
+			return runID, nil
+		}
+		runID = latest.RunID
+	}
+
+	var batch []*trash.File
+	for _, f := range files {
+		if f.RunID == runID {
+			batch = append(batch, f)
+		}
+	}
+	slices.SortFunc(batch, func(a, b *trash.File) int {
+		return strings.Compare(a.GetOriginalPath(), b.GetOriginalPath())
+	})
+	return runID, batch
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babarot/gomi/internal/config"
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// newUndoTestFiles returns two runs of files whose trash paths exist,
+// so that they pass filterFiles
+func newUndoTestFiles(t *testing.T) (string, []*trash.File) {
+	t.Helper()
+	dir := t.TempDir()
+	trashDir := filepath.Join(dir, "trash")
+	if err := os.Mkdir(trashDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	files := []*trash.File{
+		{ID: "old", RunID: "run1", DeletedAt: base},
+		{ID: "b", RunID: "run2", DeletedAt: base.Add(time.Minute)},
+		{ID: "a", RunID: "run2", DeletedAt: base.Add(time.Minute)},
+		{ID: "foreign", DeletedAt: base.Add(time.Hour)}, // trashed by another tool
+	}
+	for _, f := range files {
+		f.Name = f.ID
+		f.OriginalPath = filepath.Join(dir, f.ID)
+		f.TrashPath = filepath.Join(trashDir, f.ID)
+		if err := os.WriteFile(f.TrashPath, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, files
+}
+
+func TestSelectRun(t *testing.T) {
+	_, files := newUndoTestFiles(t)
+
+	runID, batch := selectRun(files, lastRun)
+	if runID != "run2" {
+		t.Errorf("last run = %q, want %q", runID, "run2")
+	}
+	if len(batch) != 2 || batch[0].ID != "a" || batch[1].ID != "b" {
+		t.Errorf("batch = %v, want [a b] in path order", batch)
+	}
+
+	if _, batch := selectRun(files, "run1"); len(batch) != 1 || batch[0].ID != "old" {
+		t.Errorf("run1 batch = %v, want [old]", batch)
+	}
+	if _, batch := selectRun(files, "nope"); len(batch) != 0 {
+		t.Errorf("unknown run batch = %v, want none", batch)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	_, files := newUndoTestFiles(t)
+	ft := &fakeTrash{files: files}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.Rm.Force = true
+
+	if err := cli.Undo(lastRun); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(ft.restored) != 2 || ft.restored["a"] == "" || ft.restored["b"] == "" {
+		t.Errorf("restored = %v, want a and b", ft.restored)
+	}
+}
+
+func TestUndo_Conflict(t *testing.T) {
+	dir, files := newUndoTestFiles(t)
+	if err := os.WriteFile(filepath.Join(dir, "b"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeTrash{files: files}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.Rm.Force = true
+
+	if err := cli.Undo(lastRun); !errors.Is(err, ErrUndoConflict) {
+		t.Fatalf("Undo() error = %v, want %v", err, ErrUndoConflict)
+	}
+	if len(ft.restored) != 0 {
+		t.Errorf("restored = %v, want nothing", ft.restored)
+	}
+}
+
+func TestUndo_RollsBackOnFailure(t *testing.T) {
+	dir, files := newUndoTestFiles(t)
+	ft := &fakeTrash{files: files, failID: "b"}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.Rm.Force = true
+
+	if err := cli.Undo("run2"); err == nil {
+		t.Fatal("Undo() should fail")
+	}
+	// a was restored before b failed, so it goes back to the trash as it
+	// was, rather than being trashed anew by another run
+	if len(ft.reverted) != 1 || ft.reverted[0] != filepath.Join(dir, "a") {
+		t.Errorf("reverted = %v, want [%s]", ft.reverted, filepath.Join(dir, "a"))
+	}
+	if len(ft.put) != 0 {
+		t.Errorf("put = %v, want nothing", ft.put)
+	}
+}
+
+func TestCLI_Run_UndoArgs(t *testing.T) {
+	_, files := newUndoTestFiles(t)
+	ft := &fakeTrash{files: files}
+	cli := CLI{config: config.NewDefaultConfig(), trash: ft}
+	cli.option.Undo = lastRun
+	cli.option.Rm.Force = true
+
+	// "gomi --undo run1" must not undo the last run instead of run1
+	if err := cli.Run([]string{"run1"}); !errors.Is(err, ErrExtraArgs) {
+		t.Fatalf("Run() error = %v, want %v", err, ErrExtraArgs)
+	}
+	if len(ft.restored) != 0 {
+		t.Errorf("restored = %v, want nothing", ft.restored)
+	}
+}
+
+func TestUndo_RunNotFound(t *testing.T) {
+	cli := CLI{config: config.NewDefaultConfig(), trash: &fakeTrash{}}
+	if err := cli.Undo(lastRun); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Undo() error = %v, want %v", err, ErrRunNotFound)
+	}
+}
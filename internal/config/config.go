@@ -30,12 +30,27 @@ type Core struct {
 	// Trash contains trash management configuration
 	Trash TrashConfig `yaml:"trash"`
 
+	// Put contains settings for moving files to trash
+	Put PutConfig `yaml:"put"`
+
 	// Restore contains restore-specific settings
 	Restore RestoreConfig `yaml:"restore"`
 
 	// PermanentDelete contains permanent deletion feature settings
 	PermanentDelete PermanentDeleteConfig `yaml:"permanent_delete"`
 
+	// RmCompat controls how rm options such as -i, -r and -d behave:
+	// - "loose": they are accepted and ignored, so any file can be trashed
+	// - "strict": they behave as in GNU rm
+	RmCompat string `yaml:"rm_compat" validate:"omitempty,oneof=loose strict"`
+
+	// Hooks are shell commands run around operations on files
+	Hooks HooksConfig `yaml:"hooks"`
+
+	// Rules pick what happens to files moved to trash, by path. The first
+	// matching rule applies.
+	Rules []Rule `yaml:"rules" validate:"dive"`
+
 	// Deprecated
 	TrashDir string `yaml:"trash_dir" validate:"deprecated"`
 }
@@ -55,7 +70,160 @@ type TrashConfig struct {
 	GomiDir string `yaml:"gomi_dir" validate:"omitempty,validDirPath"`
 
 	// List of forbidden paths that cannot be moved to trash
-	ForbiddenPaths []string `yaml:"forbidden_paths"`
+	ForbiddenPaths []ForbiddenPath `yaml:"forbidden_paths" validate:"dive"`
+
+	// External controls per-mount trash directories ($topdir/.Trash-$uid)
+	External ExternalTrashConfig `yaml:"external"`
+
+	// Retention limits what the trash keeps, evicting the oldest files
+	// first
+	Retention RetentionConfig `yaml:"retention"`
+}
+
+// RetentionConfig defines the limits of the trash. Zero or empty values
+// mean no limit.
+type RetentionConfig struct {
+	// MaxAge evicts files trashed longer ago than this (e.g., 30d)
+	MaxAge string `yaml:"max_age" validate:"omitempty,validDuration"`
+
+	// MaxTotalSize evicts files until the trash is at most this large
+	// (e.g., 20GB)
+	MaxTotalSize string `yaml:"max_total_size" validate:"omitempty,validSize"`
+
+	// MaxItems evicts files until the trash holds at most this many
+	MaxItems int `yaml:"max_items" validate:"omitempty,gte=0"`
+
+	// Pinned lists paths or glob patterns of original paths that are
+	// never evicted. They do not count toward the limits.
+	Pinned []string `yaml:"pinned" validate:"dive,validGlob"`
+
+	// CheckInterval is how often the limits are enforced after files are
+	// moved to trash (default: 1d)
+	CheckInterval string `yaml:"check_interval" validate:"omitempty,validDuration"`
+}
+
+// Enabled reports whether any limit is set
+func (c RetentionConfig) Enabled() bool {
+	return c.MaxAge != "" || c.MaxTotalSize != "" || c.MaxItems > 0
+}
+
+// ExternalTrashConfig defines how trash directories on other mounts are handled
+type ExternalTrashConfig struct {
+	// Create enables creating $topdir/.Trash-$uid on writable mounts that have
+	// no trash directory yet, instead of copying files into the home trash
+	Create bool `yaml:"create"`
+
+	// Mounts lists glob patterns of mount points where trash directories may be
+	// created (e.g., "/media/*"). An empty list allows any writable mount.
+	Mounts []string `yaml:"mounts"`
+}
+
+// ForbiddenPath protects paths from being moved to trash. In YAML, it is
+// either a plain path or glob, or a mapping with path or regex and mode.
+type ForbiddenPath struct {
+	// Path is a path or glob pattern (e.g., ~/src/*/.git), after ~ and
+	// environment variables are expanded. Anything under it matches too.
+	Path string `yaml:"path,omitempty"`
+
+	// Regex is matched against absolute paths, instead of Path
+	Regex string `yaml:"regex,omitempty"`
+
+	// Mode controls what happens to matching paths:
+	// - "refuse": they cannot be moved to trash (default)
+	// - "confirm": the user is asked before they are moved to trash
+	Mode string `yaml:"mode,omitempty" validate:"omitempty,oneof=refuse confirm"`
+}
+
+// UnmarshalYAML accepts a plain string as a path
+func (f *ForbiddenPath) UnmarshalYAML(unmarshal func(any) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*f = ForbiddenPath{Path: path}
+		return nil
+	}
+	type plain ForbiddenPath
+	return unmarshal((*plain)(f))
+}
+
+// Rule applies to the files under a path
+type Rule struct {
+	// Path is a path or glob pattern (e.g., ~/src/**/node_modules), after
+	// ~ and environment variables are expanded. Anything under it matches
+	// too. A relative path (e.g., target/) matches at any depth.
+	Path string `yaml:"path" validate:"required,validGlob"`
+
+	// Action is what happens to matching files:
+	// - "trash": they are moved to trash (default)
+	// - "delete": they are deleted permanently, bypassing the trash
+	// - "refuse": they cannot be removed
+	Action string `yaml:"action" validate:"omitempty,oneof=trash delete refuse"`
+
+	// Retention overrides the max_age of core.trash.retention for matching
+	// files (e.g., 7d), or keeps them forever with "never"
+	Retention string `yaml:"retention" validate:"omitempty,validRetention"`
+
+	// Storage moves matching files to this storage ("xdg" or "legacy")
+	Storage string `yaml:"storage" validate:"omitempty,oneof=xdg legacy"`
+}
+
+// HooksConfig lists the hooks of each event. A failing pre_* hook
+// vetoes the operation on that file.
+type HooksConfig struct {
+	PrePut      []Hook `yaml:"pre_put" validate:"dive"`
+	PostPut     []Hook `yaml:"post_put" validate:"dive"`
+	PreRestore  []Hook `yaml:"pre_restore" validate:"dive"`
+	PostRestore []Hook `yaml:"post_restore" validate:"dive"`
+	PostRemove  []Hook `yaml:"post_remove" validate:"dive"`
+}
+
+// Hook is a shell command run for each file of an event
+type Hook struct {
+	// Command is run with bash -c
+	Command string `yaml:"command" validate:"required"`
+
+	// Paths limits the hook to files whose original path is under one of
+	// these paths or glob patterns, after ~ and environment variables are
+	// expanded. An empty list matches any file.
+	Paths []string `yaml:"paths" validate:"dive,validGlob"`
+
+	// Timeout kills the command after this duration (e.g., 5s). The
+	// default is 30s.
+	Timeout string `yaml:"timeout" validate:"omitempty,validTimeout"`
+}
+
+// PutConfig defines settings for moving files to trash
+type PutConfig struct {
+	// Workers is the number of files moved to trash at a time.
+	// Zero uses the number of CPUs.
+	Workers int `yaml:"workers" validate:"omitempty,gte=0"`
+
+	// Progress controls the progress bar shown on stderr:
+	// - "auto": when stderr is a terminal and moving the files takes a while
+	// - "never": never show it
+	Progress string `yaml:"progress" validate:"omitempty,oneof=auto never"`
+
+	// ConfirmOver asks for confirmation before moving files beyond these
+	// limits at once
+	ConfirmOver ConfirmOverConfig `yaml:"confirm_over"`
+}
+
+// ConfirmOverConfig defines the limits of a single put. Zero or empty
+// values mean no limit.
+type ConfirmOverConfig struct {
+	// Count is the number of files and directories, including the contents
+	// of directories
+	Count int `yaml:"count" validate:"omitempty,gte=0"`
+
+	// Size is the total size, e.g. "10GB"
+	Size string `yaml:"size" validate:"omitempty,validSize"`
+
+	// Depth is the depth of the deepest directory tree
+	Depth int `yaml:"depth" validate:"omitempty,gte=0"`
+}
+
+// Enabled reports whether any limit is set
+func (c ConfirmOverConfig) Enabled() bool {
+	return c.Count > 0 || c.Size != "" || c.Depth > 0
 }
 
 // RestoreConfig defines settings for file restoration behavior
@@ -310,6 +478,12 @@ func (c *Config) validate() error {
 	_ = validate.RegisterValidation("validColorCode", validateColorCode)
 	_ = validate.RegisterValidation("deprecated", validateDeprecated)
 	_ = validate.RegisterValidation("validDirPath", validateDirPath)
+	_ = validate.RegisterValidation("validGlob", validateGlob)
+	_ = validate.RegisterValidation("validTimeout", validateTimeout)
+	_ = validate.RegisterValidation("validDuration", validateDuration)
+	_ = validate.RegisterValidation("validRetention", validateRetention)
+	validate.RegisterStructValidation(validateForbiddenPath, ForbiddenPath{})
+	validate.RegisterStructValidation(validateRule, Rule{})
 
 	if err := validate.Struct(c); err != nil {
 		var validationErrors validator.ValidationErrors
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -20,6 +21,9 @@ func TestNewDefaultConfig(t *testing.T) {
 	if cfg.Core.Trash.GomiDir == "" {
 		t.Error("GomiDir should not be empty")
 	}
+	if cfg.Core.Trash.External.Create {
+		t.Error("External.Create should be opt-in")
+	}
 	if cfg.History.Include.Period != 365 {
 		t.Errorf("Period = %d, want 365", cfg.History.Include.Period)
 	}
@@ -33,7 +37,7 @@ func TestNewDefaultConfig(t *testing.T) {
 	// Forbidden paths must include critical system dirs
 	forbiddenSet := make(map[string]bool)
 	for _, p := range cfg.Core.Trash.ForbiddenPaths {
-		forbiddenSet[p] = true
+		forbiddenSet[p.Path] = true
 	}
 	for _, p := range []string{"/", "/etc", "/usr", "/var", "/bin", "/sbin"} {
 		if !forbiddenSet[p] {
@@ -132,6 +136,40 @@ func TestConfig_Validate_InvalidColor(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate_InvalidRmCompat(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Core.RmCompat = "gnu"
+	if err := cfg.validate(); err == nil {
+		t.Error("expected validation error for invalid rm_compat")
+	}
+}
+
+func TestConfig_Validate_InvalidPut(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Core.Put.Workers = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("expected validation error for negative workers")
+	}
+
+	cfg = NewDefaultConfig()
+	cfg.Core.Put.Progress = "always"
+	if err := cfg.validate(); err == nil {
+		t.Error("expected validation error for invalid progress")
+	}
+
+	cfg = NewDefaultConfig()
+	cfg.Core.Put.ConfirmOver.Size = "lots"
+	if err := cfg.validate(); err == nil {
+		t.Error("expected validation error for invalid confirm_over size")
+	}
+
+	cfg = NewDefaultConfig()
+	cfg.Core.Put.ConfirmOver.Count = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("expected validation error for negative confirm_over count")
+	}
+}
+
 func TestConfig_SetDefault(t *testing.T) {
 	cfg := &Config{}
 	cfg.setDefault()
@@ -209,3 +247,139 @@ history:
 		t.Errorf("Period = %d, want 30", cfg.History.Include.Period)
 	}
 }
+
+func TestConfig_Load_ForbiddenPaths(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `core:
+  trash:
+    strategy: xdg
+    forbidden_paths:
+      - /etc
+      - ~/src/*/.git
+      - path: ~/important
+        mode: confirm
+      - regex: '/\.ssh(/|$)'
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{}
+	if err := cfg.load(configPath); err != nil {
+		t.Fatal(err)
+	}
+	want := []ForbiddenPath{
+		{Path: "/etc"},
+		{Path: "~/src/*/.git"},
+		{Path: "~/important", Mode: "confirm"},
+		{Regex: `/\.ssh(/|$)`},
+	}
+	if !slices.Equal(cfg.Core.Trash.ForbiddenPaths, want) {
+		t.Errorf("ForbiddenPaths = %+v, want %+v", cfg.Core.Trash.ForbiddenPaths, want)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
+
+func TestConfig_Validate_InvalidForbiddenPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry ForbiddenPath
+	}{
+		{"empty", ForbiddenPath{}},
+		{"path and regex", ForbiddenPath{Path: "/etc", Regex: "^/etc"}},
+		{"invalid regex", ForbiddenPath{Regex: "(["}},
+		{"invalid glob", ForbiddenPath{Path: "/src/[a"}},
+		{"invalid mode", ForbiddenPath{Path: "/etc", Mode: "ask"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.Core.Trash.ForbiddenPaths = []ForbiddenPath{tt.entry}
+			if err := cfg.validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Hooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr bool
+	}{
+		{"valid", Hook{Command: "true", Paths: []string{"~/work/**"}, Timeout: "5s"}, false},
+		{"no command", Hook{Paths: []string{"/tmp"}}, true},
+		{"invalid glob", Hook{Command: "true", Paths: []string{"/src/[a"}}, true},
+		{"invalid timeout", Hook{Command: "true", Timeout: "5"}, true},
+		{"zero timeout", Hook{Command: "true", Timeout: "0s"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.Core.Hooks.PreRestore = []Hook{tt.hook}
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Retention(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention RetentionConfig
+		wantErr   bool
+	}{
+		{"valid", RetentionConfig{MaxAge: "30d", MaxTotalSize: "20GB", MaxItems: 5000, Pinned: []string{"~/keep/**"}, CheckInterval: "12h"}, false},
+		{"invalid max_age", RetentionConfig{MaxAge: "30"}, true},
+		{"invalid max_total_size", RetentionConfig{MaxTotalSize: "big"}, true},
+		{"negative max_items", RetentionConfig{MaxItems: -1}, true},
+		{"invalid pinned glob", RetentionConfig{Pinned: []string{"/src/[a"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.Core.Trash.Retention = tt.retention
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_Rules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"trash with retention", Rule{Path: "~/Downloads", Retention: "7d", Storage: "legacy"}, false},
+		{"never expire", Rule{Path: "~/Documents", Action: "trash", Retention: "never"}, false},
+		{"delete", Rule{Path: "~/src/**/node_modules", Action: "delete"}, false},
+		{"relative", Rule{Path: "target/", Action: "delete"}, false},
+		{"relative out of the directory", Rule{Path: "../target", Action: "delete"}, true},
+		{"current directory", Rule{Path: "./", Action: "delete"}, true},
+		{"no path", Rule{Action: "refuse"}, true},
+		{"invalid action", Rule{Path: "/tmp", Action: "shred"}, true},
+		{"invalid retention", Rule{Path: "/tmp", Retention: "forever"}, true},
+		{"invalid storage", Rule{Path: "/tmp", Storage: "s3"}, true},
+		{"retention of delete", Rule{Path: "/tmp", Action: "delete", Retention: "7d"}, true},
+		{"storage of refuse", Rule{Path: "/tmp", Action: "refuse", Storage: "xdg"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			cfg.Core.Rules = []Rule{tt.rule}
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
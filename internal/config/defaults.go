@@ -16,25 +16,33 @@ func NewDefaultConfig() *Config {
 				Strategy:     "auto",
 				HomeFallback: true,
 				GomiDir:      filepath.Join(homedir, ".gomi"),
-				ForbiddenPaths: []string{
+				ForbiddenPaths: []ForbiddenPath{
 					// Default trash-related paths
-					"$HOME/.local/share/Trash",
-					"$HOME/.trash",
-					"$XDG_DATA_HOME/Trash",
-					"/tmp/Trash",
-					"/var/tmp/Trash",
+					{Path: "$HOME/.local/share/Trash"},
+					{Path: "$HOME/.trash"},
+					{Path: "$XDG_DATA_HOME/Trash"},
+					{Path: "/tmp/Trash"},
+					{Path: "/var/tmp/Trash"},
 					// gomi dir
-					"$HOME/.gomi",
+					{Path: "$HOME/.gomi"},
 					// Critical system directories
-					"/",
-					"/etc",
-					"/usr",
-					"/var",
-					"/bin",
-					"/sbin",
-					"/lib",
-					"/lib64",
+					{Path: "/"},
+					{Path: "/etc"},
+					{Path: "/usr"},
+					{Path: "/var"},
+					{Path: "/bin"},
+					{Path: "/sbin"},
+					{Path: "/lib"},
+					{Path: "/lib64"},
 				},
+				External: ExternalTrashConfig{
+					Create: false,
+					Mounts: []string{},
+				},
+			},
+			Put: PutConfig{
+				Workers:  0,
+				Progress: "auto",
 			},
 			Restore: RestoreConfig{
 				Confirm: true,
@@ -43,6 +51,7 @@ func NewDefaultConfig() *Config {
 			PermanentDelete: PermanentDeleteConfig{
 				Enable: false,
 			},
+			RmCompat: "loose",
 		},
 		UI: UI{
 			Density: "spacious",
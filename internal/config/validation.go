@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gobwas/glob"
+
+	"github.com/babarot/gomi/internal/utils/duration"
 )
 
 // validateStrategy validates the trash strategy value
@@ -38,6 +41,75 @@ func validateColorCode(fl validator.FieldLevel) bool {
 	return re.MatchString(value)
 }
 
+// validateForbiddenPath checks that an entry of forbidden_paths has
+// either a path or a regex, and that its pattern compiles
+func validateForbiddenPath(sl validator.StructLevel) {
+	f := sl.Current().Interface().(ForbiddenPath)
+	switch {
+	case (f.Path == "") == (f.Regex == ""):
+		sl.ReportError(f.Path, "path", "Path", "path_or_regex", "")
+	case f.Regex != "":
+		if _, err := regexp.Compile(f.Regex); err != nil {
+			sl.ReportError(f.Regex, "regex", "Regex", "regexp", "")
+		}
+	default:
+		if _, err := glob.Compile(f.Path, filepath.Separator); err != nil {
+			sl.ReportError(f.Path, "path", "Path", "glob", "")
+		}
+	}
+}
+
+// validateGlob checks that the field compiles as a path glob pattern
+func validateGlob(fl validator.FieldLevel) bool {
+	_, err := glob.Compile(fl.Field().String(), filepath.Separator)
+	return err == nil
+}
+
+// validateTimeout checks that the field is a positive duration (e.g., "5s")
+func validateTimeout(fl validator.FieldLevel) bool {
+	d, err := time.ParseDuration(fl.Field().String())
+	return err == nil && d > 0
+}
+
+// validateDuration checks that the field is a duration with days and
+// longer units (e.g., "30d")
+func validateDuration(fl validator.FieldLevel) bool {
+	_, err := duration.Parse(fl.Field().String())
+	return err == nil
+}
+
+// validateRetention checks that the field is a duration (e.g., "7d") or
+// "never"
+func validateRetention(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if value == "never" {
+		return true
+	}
+	_, err := duration.Parse(value)
+	return err == nil
+}
+
+// validateRule checks that a relative path does not lead out of the
+// directories it matches, and that retention and storage are only set on
+// rules that move files to trash
+func validateRule(sl validator.StructLevel) {
+	r := sl.Current().Interface().(Rule)
+	if path := os.ExpandEnv(r.Path); path != "~" && !strings.HasPrefix(path, "~/") && !filepath.IsAbs(path) {
+		if clean := filepath.Clean(path); clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			sl.ReportError(r.Path, "path", "Path", "relative_path", "")
+		}
+	}
+	if r.Action == "" || r.Action == "trash" {
+		return
+	}
+	if r.Retention != "" {
+		sl.ReportError(r.Retention, "retention", "Retention", "trash_action", "")
+	}
+	if r.Storage != "" {
+		sl.ReportError(r.Storage, "storage", "Storage", "trash_action", "")
+	}
+}
+
 // expandPath expands environment variables and "~" in paths
 func expandPath(path string) (string, error) {
 	// Expand "~" to home directory
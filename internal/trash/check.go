@@ -0,0 +1,79 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// IssueKind classifies a consistency problem found in a storage
+type IssueKind string
+
+const (
+	// IssueOrphanedMetadata is metadata whose trashed file is gone
+	IssueOrphanedMetadata IssueKind = "orphaned-metadata"
+
+	// IssueOrphanedData is a trashed file without metadata
+	IssueOrphanedData IssueKind = "orphaned-data"
+
+	// IssueInvalidMetadata is metadata that cannot be parsed
+	IssueInvalidMetadata IssueKind = "invalid-metadata"
+
+	// IssuePermission is a trash directory with wrong permissions
+	IssuePermission IssueKind = "permission"
+
+	// IssueStaleBackup is a backup or temporary file that no longer
+	// reflects the current state of the storage
+	IssueStaleBackup IssueKind = "stale-backup"
+
+	// IssueEmptyDir is a leftover directory that holds no trashed files
+	IssueEmptyDir IssueKind = "empty-dir"
+)
+
+// Issue describes a single consistency problem found in a storage
+type Issue struct {
+	// Kind classifies the problem
+	Kind IssueKind
+
+	// Path is the file or directory where the problem was found
+	Path string
+
+	// Detail explains the problem in human-readable form
+	Detail string
+
+	// Fix repairs the problem. It is nil if the problem can only be
+	// repaired by hand.
+	Fix func() error
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("[%s] %s: %s", i.Kind, i.Path, i.Detail)
+}
+
+// Checker is implemented by storages that can audit their own consistency
+type Checker interface {
+	// Check returns the problems found in the storage. Nothing is
+	// modified until the Fix of an issue is called.
+	Check() ([]Issue, error)
+}
+
+// Check audits every storage that implements Checker.
+// Storages that fail to be checked are reported in the returned error,
+// along with the issues found in the others.
+func (m *Manager) Check() ([]Issue, error) {
+	var issues []Issue
+	var errs []error
+	for _, storage := range m.storages {
+		checker, ok := storage.(Checker)
+		if !ok {
+			slog.Debug("storage does not support checks", "type", storage.Info().Type)
+			continue
+		}
+		found, err := checker.Check()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check %s storage: %w", storage.Info().Type, err))
+		}
+		issues = append(issues, found...)
+	}
+	return issues, errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package trash
+
+import (
+	"errors"
+	"testing"
+)
+
+// mockCheckerStorage is a storage that reports fixed issues
+type mockCheckerStorage struct {
+	mockStorage
+	issues   []Issue
+	checkErr error
+}
+
+func (m *mockCheckerStorage) Check() ([]Issue, error) {
+	return m.issues, m.checkErr
+}
+
+func TestManager_Check(t *testing.T) {
+	errBroken := errors.New("broken")
+	m := &Manager{storages: []Storage{
+		&mockCheckerStorage{issues: []Issue{{Kind: IssuePermission, Path: "/a"}}},
+		&mockStorage{storageType: StorageTypeLegacy}, // not a Checker
+		&mockCheckerStorage{
+			mockStorage: mockStorage{storageType: StorageTypeLegacy},
+			issues:      []Issue{{Kind: IssueEmptyDir, Path: "/b"}},
+			checkErr:    errBroken,
+		},
+	}}
+
+	issues, err := m.Check()
+	if !errors.Is(err, errBroken) {
+		t.Errorf("Check() error = %v, want %v", err, errBroken)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("Check() returned %d issues, want 2", len(issues))
+	}
+	if issues[0].Path != "/a" || issues[1].Path != "/b" {
+		t.Errorf("Check() = %v, want issues of /a and /b", issues)
+	}
+}
+
+func TestIssue_String(t *testing.T) {
+	i := Issue{Kind: IssueOrphanedData, Path: "/trash/files/x", Detail: "no .trashinfo"}
+	want := "[orphaned-data] /trash/files/x: no .trashinfo"
+	if got := i.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
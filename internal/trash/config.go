@@ -35,12 +35,35 @@ type Config struct {
 	// ForceHomeTrash forces using home trash even for external devices
 	ForceHomeTrash bool
 
+	// CreateExternalTrash enables creating $topdir/.Trash-$uid on mounts
+	// that have no trash directory yet
+	CreateExternalTrash bool
+
+	// ExternalMounts restricts trash directory creation to mount points
+	// matching these glob patterns (empty allows any writable mount)
+	ExternalMounts []string
+
 	// History contains history-related configuration
 	History config.History
 
+	// RunID identifies the current gomi invocation. It is recorded with
+	// every trashed file so that a whole batch can be undone.
+	RunID string
+
+	// OnCopy is called with the number of bytes copied so far while the
+	// file at src is copied into a trash directory on another device
+	OnCopy func(src string, copied int64)
+
+	// Hook is called by Manager before and after operations on files.
+	// An error for a pre_* event vetoes the operation on that file.
+	Hook func(Event) error
+
+	// ReadOnly opens the storages for listing only: no trash directory
+	// is created, and the legacy history is neither migrated nor backed up
+	ReadOnly bool
+
 	// For legacy configuration
 	GomiDir string
-	RunID   string
 }
 
 // NewDefaultConfig creates a new Config with default values
@@ -52,6 +75,15 @@ func NewDefaultConfig() *Config {
 	}
 }
 
+// CopyProgress returns the callback passed to fs.MoveWithProgress when
+// src is put to trash, or nil if OnCopy is not set
+func (c Config) CopyProgress(src string) func(copied int64) {
+	if c.OnCopy == nil {
+		return nil
+	}
+	return func(copied int64) { c.OnCopy(src, copied) }
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.HomeTrashDir != "" {
@@ -0,0 +1,101 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// Bin is a single trash directory and the files it holds
+type Bin struct {
+	// Backend is the storage the trash directory belongs to
+	Backend StorageType
+
+	// Dir is the root of the trash directory (e.g., ~/.local/share/Trash)
+	Dir string
+
+	// Files are the trashed files stored in Dir
+	Files []*File
+
+	// Leftovers are the paths in Dir that belong to no listed file, such
+	// as data without valid metadata, to be deleted along with Files
+	Leftovers []string
+}
+
+// Emptier is implemented by trashes that can be emptied as a whole
+type Emptier interface {
+	// Bins groups files by the trash directory holding them.
+	// Trash directories holding none of the files are omitted.
+	Bins(files []*File) []Bin
+
+	// Cleanup tidies the storages of the given type after their files
+	// have been removed
+	Cleanup(backend StorageType) error
+}
+
+// LeftoverFinder is implemented by storages that keep entries List
+// skips, such as data without metadata
+type LeftoverFinder interface {
+	// Leftovers returns the paths in the trash directory dir that
+	// belong to no listed file
+	Leftovers(dir string) ([]string, error)
+}
+
+// Cleaner is implemented by storages that leave bookkeeping behind when
+// files are removed one by one
+type Cleaner interface {
+	Cleanup() error
+}
+
+// Bins groups files by the trash directory holding them, in the order
+// of the storages and of their trash directories, along with the
+// leftovers of storages that implement LeftoverFinder
+func (m *Manager) Bins(files []*File) []Bin {
+	var bins []Bin
+	for _, storage := range m.storages {
+		info := storage.Info()
+		finder, _ := storage.(LeftoverFinder)
+		for _, dir := range info.Trashes {
+			bin := Bin{Backend: info.Type, Dir: dir}
+			prefix := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+			for _, f := range files {
+				if strings.HasPrefix(f.TrashPath, prefix) {
+					bin.Files = append(bin.Files, f)
+				}
+			}
+			if finder != nil {
+				leftovers, err := finder.Leftovers(dir)
+				if err != nil {
+					slog.Warn("failed to find leftovers", "dir", dir, "error", err)
+				}
+				bin.Leftovers = leftovers
+			}
+			if len(bin.Files) > 0 || len(bin.Leftovers) > 0 {
+				bins = append(bins, bin)
+			}
+		}
+	}
+	return bins
+}
+
+// Cleanup runs the cleanup of every storage of the given type that
+// implements Cleaner
+func (m *Manager) Cleanup(backend StorageType) error {
+	var errs []error
+	for _, storage := range m.storages {
+		if storage.Info().Type != backend {
+			continue
+		}
+		cleaner, ok := storage.(Cleaner)
+		if !ok {
+			continue
+		}
+		slog.Debug("cleaning up storage", "type", backend)
+		if err := cleaner.Cleanup(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clean up %s storage: %w", backend, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package trash
+
+import (
+	"errors"
+	"testing"
+)
+
+// mockCleanerStorage is a storage that records calls to Cleanup
+type mockCleanerStorage struct {
+	mockStorage
+	cleaned    bool
+	cleanupErr error
+}
+
+func (m *mockCleanerStorage) Cleanup() error {
+	m.cleaned = true
+	return m.cleanupErr
+}
+
+func TestManager_Bins(t *testing.T) {
+	m := &Manager{storages: []Storage{
+		&mockStorage{storageType: StorageTypeXDG, trashes: []string{"/home/u/.local/share/Trash", "/media/usb/.Trash-1000", "/mnt/empty/.Trash-1000"}},
+		&mockStorage{storageType: StorageTypeLegacy, trashes: []string{"/home/u/.gomi"}},
+	}}
+	files := []*File{
+		{ID: "a", TrashPath: "/media/usb/.Trash-1000/files/a"},
+		{ID: "b", TrashPath: "/home/u/.gomi/2024/01/01/b/b.b"},
+		{ID: "c", TrashPath: "/home/u/.local/share/Trash/files/c"},
+		{ID: "d", TrashPath: "/home/u/.local/share/Trash/files/d"},
+		{ID: "e", TrashPath: "/home/u/.gomi2/e"}, // not a prefix match
+	}
+
+	bins := m.Bins(files)
+	want := []struct {
+		dir string
+		ids []string
+	}{
+		{"/home/u/.local/share/Trash", []string{"c", "d"}},
+		{"/media/usb/.Trash-1000", []string{"a"}},
+		{"/home/u/.gomi", []string{"b"}},
+	}
+	if len(bins) != len(want) {
+		t.Fatalf("Bins() returned %d bins, want %d: %+v", len(bins), len(want), bins)
+	}
+	for i, w := range want {
+		if bins[i].Dir != w.dir {
+			t.Errorf("Bins()[%d].Dir = %q, want %q", i, bins[i].Dir, w.dir)
+		}
+		var ids []string
+		for _, f := range bins[i].Files {
+			ids = append(ids, f.ID)
+		}
+		if len(ids) != len(w.ids) || (len(ids) > 0 && ids[0] != w.ids[0]) {
+			t.Errorf("Bins()[%d] files = %v, want %v", i, ids, w.ids)
+		}
+	}
+	if bins[2].Backend != StorageTypeLegacy {
+		t.Errorf("Bins()[2].Backend = %v, want legacy", bins[2].Backend)
+	}
+}
+
+// mockLeftoverStorage is a storage with leftovers in every trash directory
+type mockLeftoverStorage struct {
+	mockStorage
+}
+
+func (m *mockLeftoverStorage) Leftovers(dir string) ([]string, error) {
+	return []string{dir + "/files/orphan"}, nil
+}
+
+func TestManager_BinsLeftovers(t *testing.T) {
+	m := &Manager{storages: []Storage{
+		&mockLeftoverStorage{mockStorage{storageType: StorageTypeXDG, trashes: []string{"/home/u/.local/share/Trash"}}},
+	}}
+
+	// A trash directory holding only leftovers is still a bin
+	bins := m.Bins(nil)
+	if len(bins) != 1 || len(bins[0].Files) != 0 {
+		t.Fatalf("Bins() = %+v, want one bin without files", bins)
+	}
+	if want := "/home/u/.local/share/Trash/files/orphan"; len(bins[0].Leftovers) != 1 || bins[0].Leftovers[0] != want {
+		t.Errorf("Bins()[0].Leftovers = %v, want [%s]", bins[0].Leftovers, want)
+	}
+}
+
+func TestManager_Cleanup(t *testing.T) {
+	xdg := &mockCleanerStorage{mockStorage: mockStorage{storageType: StorageTypeXDG}}
+	legacy := &mockCleanerStorage{mockStorage: mockStorage{storageType: StorageTypeLegacy}, cleanupErr: errors.New("fail")}
+	m := &Manager{storages: []Storage{xdg, legacy, &mockStorage{storageType: StorageTypeLegacy}}}
+
+	if err := m.Cleanup(StorageTypeLegacy); err == nil {
+		t.Error("Cleanup() should return the error of the storage")
+	}
+	if xdg.cleaned || !legacy.cleaned {
+		t.Errorf("cleaned xdg = %v, legacy = %v; want only legacy", xdg.cleaned, legacy.cleaned)
+	}
+}
@@ -1,6 +1,10 @@
 package trash
 
-import "errors"
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+)
 
 // Common errors that can be returned by Storage implementations
 var (
@@ -21,8 +25,50 @@ var (
 
 	// ErrFileExists is returned when a file already exists at the target location
 	ErrFileExists = errors.New("file already exists")
+
+	// ErrVetoed is returned when a pre_* hook refuses an operation
+	ErrVetoed = errors.New("vetoed by hook")
+)
+
+// ErrorCode classifies an error for machine-readable reports
+type ErrorCode string
+
+const (
+	CodeNotFound         ErrorCode = "not_found"
+	CodeInvalidStorage   ErrorCode = "invalid_storage"
+	CodeCrossDevice      ErrorCode = "cross_device"
+	CodeStorageNotReady  ErrorCode = "storage_not_ready"
+	CodePermissionDenied ErrorCode = "permission_denied"
+	CodeFileExists       ErrorCode = "file_exists"
+	CodeVetoed           ErrorCode = "vetoed"
+	CodeUnknown          ErrorCode = "unknown"
 )
 
+// Code returns the code of err, or "" if err is nil. Errors of the os
+// package are classified along with the errors above.
+func Code(err error) ErrorCode {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, fs.ErrPermission):
+		return CodePermissionDenied
+	case errors.Is(err, ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return CodeNotFound
+	case errors.Is(err, ErrFileExists), errors.Is(err, fs.ErrExist):
+		return CodeFileExists
+	case errors.Is(err, ErrCrossDevice), errors.Is(err, syscall.EXDEV):
+		return CodeCrossDevice
+	case errors.Is(err, ErrStorageNotReady):
+		return CodeStorageNotReady
+	case errors.Is(err, ErrInvalidStorage):
+		return CodeInvalidStorage
+	case errors.Is(err, ErrVetoed):
+		return CodeVetoed
+	default:
+		return CodeUnknown
+	}
+}
+
 // StorageError wraps an error with additional context about the storage operation
 type StorageError struct {
 	// Op is the operation that failed (e.g., "put", "restore", "remove")
@@ -3,6 +3,9 @@ package trash
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
 	"testing"
 )
 
@@ -82,3 +85,29 @@ func TestErrorCheckers(t *testing.T) {
 		})
 	}
 }
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"not found", NewStorageError("restore", "/tmp/a", ErrNotFound), CodeNotFound},
+		{"os not exist", &fs.PathError{Op: "lstat", Path: "/tmp/a", Err: syscall.ENOENT}, CodeNotFound},
+		{"permission", NewStorageError("put", "/tmp/a", &fs.PathError{Op: "rename", Path: "/tmp/a", Err: syscall.EACCES}), CodePermissionDenied},
+		{"file exists", fmt.Errorf("/tmp/a: %w", ErrFileExists), CodeFileExists},
+		{"cross device", &os.LinkError{Op: "rename", Old: "/a", New: "/b", Err: syscall.EXDEV}, CodeCrossDevice},
+		{"not ready", ErrStorageNotReady, CodeStorageNotReady},
+		{"invalid storage", ErrInvalidStorage, CodeInvalidStorage},
+		{"other", errors.New("boom"), CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -94,14 +94,7 @@ func rejectByPatterns[T Filterable](items []T, patterns []string) []T {
 
 	var filtered []T
 	for _, item := range items {
-		excluded := false
-		for _, pattern := range patterns {
-			if matched, err := regexp.MatchString(pattern, item.GetName()); err == nil && matched {
-				excluded = true
-				break
-			}
-		}
-		if !excluded {
+		if !matchesPatterns(item.GetName(), patterns) {
 			filtered = append(filtered, item)
 		}
 	}
@@ -115,14 +108,7 @@ func rejectByGlobs[T Filterable](items []T, globs []string) []T {
 
 	var filtered []T
 	for _, item := range items {
-		excluded := false
-		for _, g := range globs {
-			if glob.MustCompile(g).Match(item.GetName()) {
-				excluded = true
-				break
-			}
-		}
-		if !excluded {
+		if !matchesGlobs(item.GetName(), globs) {
 			filtered = append(filtered, item)
 		}
 	}
@@ -134,38 +120,95 @@ func rejectBySize[T Filterable](items []T, size config.SizeConfig) []T {
 		return items
 	}
 
+	var min, max int64
+	if size.Min != "" {
+		min, _ = units.FromHumanSize(size.Min)
+	}
+	if size.Max != "" {
+		max, _ = units.FromHumanSize(size.Max)
+	}
+
 	var filtered []T
 	for _, item := range items {
-		// Use cached size from List(); fall back to DirSize for unknown sizes
-		fileSize := item.GetSize()
-		if fileSize == 0 {
-			var err error
-			fileSize, err = fs.DirSize(item.GetPath())
-			if err != nil {
-				continue // Skip items we can't size
-			}
+		if sizeWithin(item, min, max) {
+			filtered = append(filtered, item)
 		}
+	}
+	return filtered
+}
 
-		include := true
-		if size.Min != "" {
-			if min, err := units.FromHumanSize(size.Min); err == nil {
-				if fileSize < min {
-					include = false
-				}
-			}
+// matchesPatterns reports whether name matches any of the regular expressions
+func matchesPatterns(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := regexp.MatchString(pattern, name); err == nil && matched {
+			return true
 		}
-		if size.Max != "" {
-			if max, err := units.FromHumanSize(size.Max); err == nil {
-				if max < fileSize {
-					include = false
-				}
-			}
+	}
+	return false
+}
+
+// matchesGlobs reports whether name matches any of the glob patterns
+func matchesGlobs(name string, globs []string) bool {
+	for _, g := range globs {
+		if glob.MustCompile(g).Match(name) {
+			return true
 		}
-		if include {
-			filtered = append(filtered, item)
+	}
+	return false
+}
+
+// sizeWithin reports whether the size of item is within [min, max].
+// A zero bound means no limit. Items that cannot be sized never match.
+func sizeWithin[T Filterable](item T, min, max int64) bool {
+	// Use cached size from List(); fall back to DirSize for unknown sizes
+	size := item.GetSize()
+	if size == 0 {
+		var err error
+		size, err = fs.DirSize(item.GetPath())
+		if err != nil {
+			return false
 		}
 	}
-	return filtered
+	if min > 0 && size < min {
+		return false
+	}
+	if max > 0 && max < size {
+		return false
+	}
+	return true
+}
+
+// SelectOptions holds criteria to pick trashed files, e.g. for pruning.
+// Unlike FilterOptions, items matching the criteria are kept.
+type SelectOptions struct {
+	// Globs keeps items whose name matches any of the glob patterns
+	Globs []string
+
+	// Patterns keeps items whose name matches any of the regular expressions
+	Patterns []string
+
+	// MinSize and MaxSize keep items within the size range in bytes.
+	// Zero means no limit.
+	MinSize int64
+	MaxSize int64
+}
+
+// Select returns the items that match every given criterion
+func Select[T Filterable](items []T, opts SelectOptions) []T {
+	var selected []T
+	for _, item := range items {
+		if len(opts.Globs) > 0 && !matchesGlobs(item.GetName(), opts.Globs) {
+			continue
+		}
+		if len(opts.Patterns) > 0 && !matchesPatterns(item.GetName(), opts.Patterns) {
+			continue
+		}
+		if (opts.MinSize > 0 || opts.MaxSize > 0) && !sizeWithin(item, opts.MinSize, opts.MaxSize) {
+			continue
+		}
+		selected = append(selected, item)
+	}
+	return selected
 }
 
 func filterByPeriod[T Filterable](items []T, period int) []T {
@@ -150,3 +150,38 @@ func TestFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestSelect(t *testing.T) {
+	items := []TestItem{
+		{name: "small.iso", path: "/trash/small.iso", size: 100},
+		{name: "big.iso", path: "/trash/big.iso", size: 5000},
+		{name: "big.log", path: "/trash/big.log", size: 5000},
+	}
+
+	testCases := []struct {
+		name          string
+		opts          SelectOptions
+		expectedNames []string
+	}{
+		{"No criteria", SelectOptions{}, []string{"small.iso", "big.iso", "big.log"}},
+		{"Glob", SelectOptions{Globs: []string{"*.iso"}}, []string{"small.iso", "big.iso"}},
+		{"Pattern", SelectOptions{Patterns: []string{`\.log$`}}, []string{"big.log"}},
+		{"Min size", SelectOptions{MinSize: 1000}, []string{"big.iso", "big.log"}},
+		{"Max size", SelectOptions{MaxSize: 1000}, []string{"small.iso"}},
+		{"Combined", SelectOptions{Globs: []string{"*.iso"}, MinSize: 1000}, []string{"big.iso"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected := Select(items, tc.opts)
+			if len(selected) != len(tc.expectedNames) {
+				t.Fatalf("Expected %d items, got %d", len(tc.expectedNames), len(selected))
+			}
+			for i, item := range selected {
+				if item.GetName() != tc.expectedNames[i] {
+					t.Errorf("Expected %s at index %d, got %s", tc.expectedNames[i], i, item.GetName())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package trash
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// Events passed to Config.Hook
+const (
+	EventPrePut      = "pre_put"
+	EventPostPut     = "post_put"
+	EventPreRestore  = "pre_restore"
+	EventPostRestore = "post_restore"
+	EventPostRemove  = "post_remove"
+)
+
+// Event describes an operation on a file
+type Event struct {
+	// Name is one of the Event* constants
+	Name string
+
+	// File is the file the operation is about. For pre_put, the trash
+	// path and backend are where the file would go, if it can be planned.
+	File *File
+
+	// Dst is where the file is restored to, for restore events
+	Dst string
+}
+
+// IsPre reports whether the event comes before the operation, which it
+// can veto
+func (e Event) IsPre() bool {
+	return strings.HasPrefix(e.Name, "pre_")
+}
+
+// hook calls Config.Hook, if set. Errors of post events are only logged,
+// as the operation is already done.
+func (m *Manager) hook(ev Event) error {
+	if m.config.Hook == nil {
+		return nil
+	}
+	err := m.config.Hook(ev)
+	if err != nil && !ev.IsPre() {
+		slog.Warn("hook failed", "event", ev.Name, "path", ev.File.OriginalPath, "error", err)
+		return nil
+	}
+	return err
+}
+
+// prePut runs the pre_put hook for path, telling where it would go among
+// storages
+func (m *Manager) prePut(path string, isDir bool, storages []Storage) error {
+	if m.config.Hook == nil {
+		return nil
+	}
+	file := &File{Name: filepath.Base(path), OriginalPath: path, IsDir: isDir}
+	if plan, err := m.planPut(path, storages); err == nil {
+		file.TrashPath, file.Backend = plan.TrashPath, plan.Backend
+	}
+	return m.hook(Event{Name: EventPrePut, File: file})
+}
@@ -0,0 +1,118 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// recordHooks returns a hook that records the events and fails for the
+// events in fail
+func recordHooks(events *[]string, fail ...string) func(Event) error {
+	return func(ev Event) error {
+		*events = append(*events, ev.Name)
+		if slices.Contains(fail, ev.Name) {
+			return errors.New("hook failed")
+		}
+		return nil
+	}
+}
+
+func TestManager_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := &File{OriginalPath: src, TrashPath: "/trash/files/a.txt"}
+	dst := filepath.Join(dir, "restored.txt")
+
+	tests := []struct {
+		name    string
+		fail    []string
+		op      func(m *Manager) error
+		want    []string
+		wantErr bool
+	}{
+		{"put", nil, func(m *Manager) error { return m.Put(src) }, []string{EventPrePut, EventPostPut}, false},
+		{"put vetoed", []string{EventPrePut}, func(m *Manager) error { return m.Put(src) }, []string{EventPrePut}, true},
+		{"failing post_put", []string{EventPostPut}, func(m *Manager) error { return m.Put(src) }, []string{EventPrePut, EventPostPut}, false},
+		{"restore", nil, func(m *Manager) error { return m.Restore(file, dst) }, []string{EventPreRestore, EventPostRestore}, false},
+		{"restore vetoed", []string{EventPreRestore}, func(m *Manager) error { return m.Restore(file, dst) }, []string{EventPreRestore}, true},
+		{"remove", nil, func(m *Manager) error { return m.Remove(file) }, []string{EventPostRemove}, false},
+		{"delete vetoed", []string{EventPrePut}, func(m *Manager) error { return m.Delete(src) }, []string{EventPrePut}, true},
+		{"delete", nil, func(m *Manager) error { return m.Delete(src) }, []string{EventPrePut, EventPostRemove}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			m := &Manager{
+				storages: []Storage{&mockPlannerStorage{mockStorage: mockStorage{trashes: []string{"/trash"}}}},
+				config:   Config{Hook: recordHooks(&events, tt.fail...)},
+			}
+			if err := tt.op(m); (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(events, tt.want) {
+				t.Errorf("events = %v, want %v", events, tt.want)
+			}
+		})
+	}
+}
+
+type mockReverterStorage struct {
+	mockStorage
+}
+
+func (m *mockReverterStorage) RevertRestore(*File, string) error { return nil }
+
+func TestManager_RevertRestoreHooks(t *testing.T) {
+	var events []string
+	m := &Manager{
+		storages: []Storage{&mockReverterStorage{mockStorage{trashes: []string{"/trash"}}}},
+		config:   Config{Hook: recordHooks(&events, EventPrePut)},
+	}
+	file := &File{OriginalPath: "/tmp/a.txt", TrashPath: "/trash/files/a.txt"}
+	if err := m.RevertRestore(file, file.OriginalPath); err != nil {
+		t.Fatalf("RevertRestore() error = %v", err)
+	}
+	if want := []string{EventPostPut}; !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestManager_PrePutPlan(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got *File
+	m := &Manager{
+		storages: []Storage{&mockPlannerStorage{mockStorage: mockStorage{storageType: StorageTypeLegacy}}},
+		config: Config{Hook: func(ev Event) error {
+			if ev.Name == EventPrePut {
+				got = ev.File
+			}
+			return nil
+		}},
+	}
+	if err := m.Put(src); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.OriginalPath != src || got.Backend != StorageTypeLegacy {
+		t.Errorf("pre_put file = %+v, want %s planned to the legacy storage", got, src)
+	}
+
+	// A routed put is planned to the storage it goes to
+	m.storages = append([]Storage{&mockPlannerStorage{mockStorage: mockStorage{storageType: StorageTypeXDG}}}, m.storages...)
+	if _, err := m.PutFileTo(src, StorageTypeLegacy); err != nil {
+		t.Fatal(err)
+	}
+	if got.Backend != StorageTypeLegacy {
+		t.Errorf("pre_put backend of a routed put = %v, want %v", got.Backend, StorageTypeLegacy)
+	}
+}
@@ -0,0 +1,194 @@
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/trash/legacy/history"
+)
+
+// layoutDepth is the depth of the ID directories in ~/.gomi/YYYY/MM/DD/ID.
+// Anything below them is trashed content.
+const layoutDepth = 4
+
+// Check audits history.json against the files under ~/.gomi.
+// It implements trash.Checker.
+func (s *Storage) Check() ([]trash.Issue, error) {
+	var issues []trash.Issue
+
+	s.mu.Lock()
+	files := slices.Clone(s.history.Files)
+	s.mu.Unlock()
+
+	for _, f := range files {
+		if _, err := os.Lstat(f.To); !os.IsNotExist(err) {
+			continue
+		}
+		issues = append(issues, trash.Issue{
+			Kind:   trash.IssueOrphanedMetadata,
+			Path:   f.To,
+			Detail: fmt.Sprintf("history entry for %s points to a missing file", f.From),
+			Fix:    func() error { return s.forget(f.To) },
+		})
+	}
+
+	stale, err := s.checkTempFiles()
+	if err != nil {
+		return issues, err
+	}
+	issues = append(issues, stale...)
+
+	dirs, err := findEmptyDirs(s.root)
+	if err != nil {
+		return issues, fmt.Errorf("failed to scan %s: %w", s.root, err)
+	}
+	for _, dir := range dirs {
+		issues = append(issues, trash.Issue{
+			Kind:   trash.IssueEmptyDir,
+			Path:   dir,
+			Detail: "directory holds no trashed files",
+			Fix:    func() error { return removeEmptyDirs(dir) },
+		})
+	}
+
+	return issues, nil
+}
+
+// forget removes the history entry of a trashed file
+func (s *Storage) forget(trashPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.RemoveByPath(trashPath)
+	return s.saveHistory()
+}
+
+// Cleanup forgets history entries whose trashed file is gone and removes
+// the date and ID directories left empty by Remove.
+// It implements trash.Cleaner.
+func (s *Storage) Cleanup() error {
+	s.mu.Lock()
+	kept := slices.DeleteFunc(slices.Clone(s.history.Files), func(f history.File) bool {
+		_, err := os.Lstat(f.To)
+		return os.IsNotExist(err)
+	})
+	var err error
+	if len(kept) != len(s.history.Files) {
+		s.history.Files = kept
+		err = s.saveHistory()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+
+	dirs, err := findEmptyDirs(s.root)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", s.root, err)
+	}
+	var errs []error
+	for _, dir := range dirs {
+		if err := removeEmptyDirs(dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkTempFiles reports temporary files left behind by interrupted
+// history saves. history.json.backup is not checked, as opening the
+// history rewrites it.
+func (s *Storage) checkTempFiles() ([]trash.Issue, error) {
+	temps, err := filepath.Glob(filepath.Join(s.root, ".history.*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var issues []trash.Issue
+	for _, tmp := range temps {
+		issues = append(issues, trash.Issue{
+			Kind:   trash.IssueStaleBackup,
+			Path:   tmp,
+			Detail: "temporary file left by an interrupted history save",
+			Fix:    func() error { return os.Remove(tmp) },
+		})
+	}
+	return issues, nil
+}
+
+// findEmptyDirs returns the topmost date and ID directories under root
+// that contain no trashed files
+func findEmptyDirs(root string) ([]string, error) {
+	var found []string
+
+	var walk func(dir string, depth int) (bool, error)
+	walk = func(dir string, depth int) (bool, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return false, err
+		}
+
+		empty := true
+		var emptyChildren []string
+		for _, entry := range entries {
+			// Files directly under root (history.json etc.) are not
+			// inspected, and everything inside an ID directory is content
+			if !entry.IsDir() || depth == layoutDepth {
+				if depth > 0 {
+					empty = false
+				}
+				continue
+			}
+			child := filepath.Join(dir, entry.Name())
+			childEmpty, err := walk(child, depth+1)
+			if err != nil {
+				return false, err
+			}
+			if childEmpty {
+				emptyChildren = append(emptyChildren, child)
+			} else {
+				empty = false
+			}
+		}
+
+		// Report the children only when this directory is kept,
+		// so that a whole empty subtree shows up once
+		if !empty || depth == 0 {
+			found = append(found, emptyChildren...)
+		}
+		return empty, nil
+	}
+
+	if _, err := walk(root, 0); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// removeEmptyDirs removes dir and its subdirectories bottom-up.
+// os.Remove refuses non-empty directories, so files created since the
+// check are never deleted.
+func removeEmptyDirs(dir string) error {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range slices.Backward(dirs) {
+		if err := os.Remove(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package legacy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/babarot/gomi/internal/trash"
+	"github.com/babarot/gomi/internal/trash/legacy/history"
+)
+
+func TestStorage_Check(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(newTestConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srcDir := t.TempDir()
+	for _, name := range []string{"kept.txt", "gone.txt"} {
+		src := filepath.Join(srcDir, name)
+		if err := os.WriteFile(src, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Put(src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := s.List()
+	if err != nil || len(files) != 2 {
+		t.Fatalf("List() = %v, %v", files, err)
+	}
+	var gone string
+	for _, f := range files {
+		if f.Name == "gone.txt" {
+			gone = f.TrashPath
+		}
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leftover date directory without any trashed file
+	emptyDir := filepath.Join(dir, "2020")
+	if err := os.MkdirAll(filepath.Join(emptyDir, "01", "02", "id"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Temporary file of an interrupted history save
+	tmp := filepath.Join(dir, ".history.123.json")
+	if err := os.WriteFile(tmp, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := s.(trash.Checker).Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	want := map[string]trash.IssueKind{
+		gone:               trash.IssueOrphanedMetadata,
+		tmp:                trash.IssueStaleBackup,
+		emptyDir:           trash.IssueEmptyDir,
+		filepath.Dir(gone): trash.IssueEmptyDir,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Check() returned %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for _, issue := range issues {
+		if want[issue.Path] != issue.Kind {
+			t.Errorf("unexpected issue %v", issue)
+		}
+	}
+	for _, issue := range issues {
+		if err := issue.Fix(); err != nil {
+			t.Fatalf("Fix() for %v error = %v", issue, err)
+		}
+	}
+
+	issues, err = s.(trash.Checker).Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Check() after fix returned %v, want none", issues)
+	}
+
+	files, err = s.List()
+	if err != nil || len(files) != 1 {
+		t.Errorf("List() after fix = %v, %v", files, err)
+	}
+}
+
+func TestFindEmptyDirs(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(parts ...string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(append([]string{root}, parts...)...), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A trashed empty directory is content, not a leftover
+	mkdir("2024", "01", "01", "id1", "emptydir.id1")
+	// Whole empty subtree is reported once at its top
+	mkdir("2024", "02", "01", "id2")
+	mkdir("2024", "02", "02")
+	if err := os.WriteFile(filepath.Join(root, "history.json"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := findEmptyDirs(root)
+	if err != nil {
+		t.Fatalf("findEmptyDirs() error = %v", err)
+	}
+	want := filepath.Join(root, "2024", "02")
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("findEmptyDirs() = %v, want [%s]", got, want)
+	}
+
+	if err := removeEmptyDirs(want); err != nil {
+		t.Fatalf("removeEmptyDirs() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "2024", "01", "01", "id1", "emptydir.id1")); err != nil {
+		t.Errorf("trashed directory should be kept: %v", err)
+	}
+}
+
+func TestStorage_Cleanup(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(newTestConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(src, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(src); err != nil {
+		t.Fatal(err)
+	}
+	files, err := s.List()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("List() = %v, %v", files, err)
+	}
+	if err := s.Remove(files[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry whose file was deleted by hand
+	storage := s.(*Storage)
+	storage.history.Add(history.File{Name: "b.txt", ID: "b", From: "/tmp/b.txt", To: filepath.Join(dir, "2020", "01", "02", "b", "b.txt.b")})
+
+	if err := storage.Cleanup(); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if len(storage.history.Files) != 0 {
+		t.Errorf("history after Cleanup() = %v, want empty", storage.history.Files)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			t.Errorf("directory %s should have been removed", entry.Name())
+		}
+	}
+}
@@ -37,6 +37,10 @@ type File struct {
 	From      string    `json:"from"`
 	To        string    `json:"to"`
 	Timestamp time.Time `json:"timestamp"`
+
+	// Metadata holds the details recorded when the file was trashed,
+	// missing for files trashed by older versions
+	Metadata *trash.Metadata `json:"metadata,omitempty"`
 }
 
 func (f File) GetName() string {
@@ -59,7 +63,6 @@ func New(home string, c config.History) History {
 	if home == "" {
 		home = filepath.Join(os.Getenv("HOME"), ".gomi")
 	}
-	migrateIfNeeded(home)
 	return History{
 		home:   home,
 		path:   filepath.Join(home, Filename),
@@ -69,8 +72,9 @@ func New(home string, c config.History) History {
 
 func (h *History) Open() error {
 	slog.Debug("opening history file", "path", h.path)
+	migrateIfNeeded(h.home)
 	defer func() {
-		_ = h.backup()
+		_ = h.Backup()
 		slog.Debug("backed up")
 	}()
 
@@ -113,7 +117,24 @@ func (h *History) Open() error {
 	return nil
 }
 
-func (h *History) backup() error {
+// Read loads the history without changing any file, unlike Open. A
+// missing history is empty.
+func (h *History) Read() error {
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, h)
+}
+
+// Backup writes the current history to history.json.backup
+func (h *History) Backup() error {
 	backupFile := h.path + ".backup"
 	slog.Debug("backing up history", "path", backupFile)
 	f, err := os.Create(backupFile)
@@ -130,7 +151,7 @@ func (h *History) backup() error {
 func (h *History) Update(files []File) error {
 	slog.Debug("updating history file", "path", h.path)
 	defer func() {
-		_ = h.backup()
+		_ = h.Backup()
 		slog.Debug("backed up")
 	}()
 	f, err := os.Create(h.path)
@@ -149,7 +170,7 @@ func (h *History) Update(files []File) error {
 func (h *History) Save() error {
 	slog.Debug("saving history file", "path", h.path)
 	defer func() {
-		_ = h.backup()
+		_ = h.Backup()
 		slog.Debug("backed up")
 	}()
 	f, err := os.Create(h.path)
@@ -162,7 +183,7 @@ func (h *History) Save() error {
 
 func (h *History) Remove(target File) error {
 	defer func() {
-		_ = h.backup()
+		_ = h.Backup()
 		slog.Debug("backed up")
 	}()
 	slog.Debug("deleting file from history file", "path", h.path, "file", target)
@@ -280,7 +280,7 @@ func TestHistory_Backup(t *testing.T) {
 	h.Files = []File{{Name: "test.txt"}}
 	h.Version = 1
 
-	if err := h.backup(); err != nil {
+	if err := h.Backup(); err != nil {
 		t.Fatal(err)
 	}
 
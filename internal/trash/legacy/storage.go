@@ -59,6 +59,13 @@ func NewStorage(cfg trash.Config) (trash.Storage, error) {
 		"gomiDir", cfg.GomiDir,
 		"historyPath", s.historyPath)
 
+	if cfg.ReadOnly {
+		if err := s.history.Read(); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		return s, nil
+	}
+
 	// Create trash directory if it doesn't exist
 	if err := os.MkdirAll(root, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create trash directory: %w", err)
@@ -82,55 +89,114 @@ func (s *Storage) Info() *trash.StorageInfo {
 }
 
 func (s *Storage) Put(src string) error {
+	_, err := s.PutFile(src)
+	return err
+}
+
+// PutFile moves src to trash and returns the trashed file.
+// It implements trash.Putter.
+func (s *Storage) PutFile(src string) (*trash.File, error) {
 	// Get absolute path
 	abs, err := filepath.Abs(src)
 	if err != nil {
-		return trash.NewStorageError("put", src, err)
+		return nil, trash.NewStorageError("put", src, err)
 	}
 
 	id := uuid.New().String()
+	runID := s.config.RunID
+	if runID == "" {
+		// Older versions stored the file ID here
+		runID = id
+	}
 	trashName := fmt.Sprintf("%s.%s", filepath.Base(abs), id)
 	trashPath := filepath.Join(s.root, time.Now().Format("2006/01/02"), id, trashName)
 
 	// Create parent directories
 	if err := os.MkdirAll(filepath.Dir(trashPath), 0700); err != nil {
-		return trash.NewStorageError("put", src, err)
+		return nil, trash.NewStorageError("put", src, err)
 	}
 
+	// Recorded before the move, which may not preserve the attributes
+	meta := trash.NewMetadata(abs)
+
 	// Move file to trash (with fallback copy for cross-device moves)
-	if err := fs.Move(abs, trashPath, true); err != nil {
-		return trash.NewStorageError("put", src, err)
+	if err := fs.MoveWithProgress(abs, trashPath, true, s.config.CopyProgress(abs)); err != nil {
+		return nil, trash.NewStorageError("put", src, err)
 	}
 
 	// Add to history (protected by mutex for concurrent Put calls)
-	s.mu.Lock()
-	s.history.Add(history.File{
+	entry := history.File{
 		Name:      filepath.Base(abs),
 		ID:        id,
-		RunID:     id, // For compatibility with old format
+		RunID:     runID,
 		From:      abs,
 		To:        trashPath,
 		Timestamp: time.Now(),
-	})
+		Metadata:  meta,
+	}
+	s.mu.Lock()
+	s.history.Add(entry)
 
 	// Save history
 	if err := s.saveHistory(); err != nil {
 		s.mu.Unlock()
 		// Try to roll back the file move
 		if moveErr := fs.Move(trashPath, abs, true); moveErr != nil {
-			return trash.NewStorageError(
+			return nil, trash.NewStorageError(
 				"put",
 				src,
 				fmt.Errorf("failed to save history and rollback failed: %w (original error: %w)", moveErr, err))
 		}
-		return trash.NewStorageError(
+		return nil, trash.NewStorageError(
 			"put",
 			src,
 			fmt.Errorf("failed to save history: %w", err))
 	}
 	s.mu.Unlock()
 
-	return nil
+	file := &trash.File{
+		ID:           entry.ID,
+		Name:         entry.Name,
+		OriginalPath: entry.From,
+		TrashPath:    entry.To,
+		DeletedAt:    entry.Timestamp,
+		Backend:      trash.StorageTypeLegacy,
+		RunID:        entry.RunID,
+		Metadata:     entry.Metadata,
+	}
+	if fi, err := os.Lstat(trashPath); err == nil {
+		file.IsDir = fi.IsDir()
+		file.FileMode = fi.Mode()
+		if !fi.IsDir() {
+			file.Size = fi.Size()
+		}
+	}
+
+	return file, nil
+}
+
+// PlanPut returns what Put would do with src without touching the
+// filesystem. The trashed name gets a new ID on every Put, so TrashPath
+// only shows the layout.
+func (s *Storage) PlanPut(src string) (*trash.Plan, error) {
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return nil, trash.NewStorageError("plan", src, err)
+	}
+
+	id := "<id>"
+	trashName := fmt.Sprintf("%s.%s", filepath.Base(abs), id)
+	plan := &trash.Plan{
+		Path:      abs,
+		Backend:   trash.StorageTypeLegacy,
+		TrashDir:  s.root,
+		TrashPath: filepath.Join(s.root, time.Now().Format("2006/01/02"), id, trashName),
+	}
+	if same, err := fs.SameDevice(abs, s.root); err == nil && !same {
+		plan.CrossDevice = true
+	}
+
+	return plan, nil
 }
 
 func (s *Storage) List() ([]*trash.File, error) {
@@ -143,15 +209,21 @@ func (s *Storage) List() ([]*trash.File, error) {
 	for _, f := range filtered {
 		// Convert legacy File to trash.File
 		file := &trash.File{
+			ID:           f.ID,
 			Name:         f.Name,
 			OriginalPath: f.From,
 			TrashPath:    f.To,
 			DeletedAt:    f.Timestamp,
+			RunID:        f.RunID,
+			Metadata:     f.Metadata,
 		}
 
 		// Get additional file info
 		if info, err := os.Stat(f.To); err == nil {
-			file.Size = info.Size()
+			if !info.IsDir() {
+				// Directory sizes are unknown here and computed on demand
+				file.Size = info.Size()
+			}
 			file.IsDir = info.IsDir()
 			file.FileMode = info.Mode()
 		}
@@ -189,6 +261,37 @@ func (s *Storage) Restore(file *trash.File, dst string) error {
 	return nil
 }
 
+// RevertRestore moves src back to the trash path of file and adds it to
+// the history again, so that the file is listed as before it was restored.
+// It implements trash.Reverter.
+func (s *Storage) RevertRestore(file *trash.File, src string) error {
+	if _, err := os.Lstat(file.TrashPath); !os.IsNotExist(err) {
+		return trash.NewStorageError("revert", src, fmt.Errorf("%s: %w", file.TrashPath, trash.ErrFileExists))
+	}
+	if err := os.MkdirAll(filepath.Dir(file.TrashPath), 0700); err != nil {
+		return trash.NewStorageError("revert", src, err)
+	}
+	if err := fs.Move(src, file.TrashPath, true); err != nil {
+		return trash.NewStorageError("revert", src, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history.Add(history.File{
+		Name:      file.Name,
+		ID:        file.ID,
+		RunID:     file.RunID,
+		From:      file.OriginalPath,
+		To:        file.TrashPath,
+		Timestamp: file.DeletedAt,
+		Metadata:  file.Metadata,
+	})
+	if err := s.saveHistory(); err != nil {
+		return trash.NewStorageError("revert", src, fmt.Errorf("failed to save history: %w", err))
+	}
+	return nil
+}
+
 func (s *Storage) Remove(file *trash.File) error {
 	// Remove the actual file
 	if err := os.RemoveAll(file.TrashPath); err != nil {
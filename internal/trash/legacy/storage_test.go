@@ -3,6 +3,7 @@ package legacy
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/babarot/gomi/internal/config"
@@ -43,6 +44,47 @@ func TestNewStorage(t *testing.T) {
 	}
 }
 
+func TestNewStorage_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(newTestConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(src); err != nil {
+		t.Fatal(err)
+	}
+	backup := filepath.Join(dir, "history.json.backup")
+	if err := os.Remove(backup); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(dir)
+	cfg.ReadOnly = true
+	s, err = NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	files, err := s.List()
+	if err != nil || len(files) != 1 || files[0].OriginalPath != src {
+		t.Errorf("List() = %v, %v, want %s", files, err, src)
+	}
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		t.Error("a read-only storage should not back up the history")
+	}
+
+	cfg.GomiDir = filepath.Join(t.TempDir(), "none")
+	if _, err := NewStorage(cfg); err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	if _, err := os.Stat(cfg.GomiDir); !os.IsNotExist(err) {
+		t.Error("a read-only storage should not create the trash directory")
+	}
+}
+
 func TestStorage_PutAndList(t *testing.T) {
 	dir := t.TempDir()
 	cfg := newTestConfig(dir)
@@ -83,6 +125,12 @@ func TestStorage_PutAndList(t *testing.T) {
 	if files[0].OriginalPath != srcFile {
 		t.Errorf("OriginalPath = %q, want %q", files[0].OriginalPath, srcFile)
 	}
+	if files[0].RunID != "test-run" {
+		t.Errorf("RunID = %q, want %q", files[0].RunID, "test-run")
+	}
+	if want := "test.txt." + files[0].ID; filepath.Base(files[0].TrashPath) != want {
+		t.Errorf("TrashPath = %q, want base name %q", files[0].TrashPath, want)
+	}
 }
 
 func TestStorage_Restore(t *testing.T) {
@@ -139,6 +187,40 @@ func TestStorage_Restore(t *testing.T) {
 	}
 }
 
+func TestStorage_RevertRestore(t *testing.T) {
+	s, err := NewStorage(newTestConfig(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srcFile := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(srcFile, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := s.(trash.Putter).PutFile(srcFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(before, srcFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.(trash.Reverter).RevertRestore(before, srcFile); err != nil {
+		t.Fatalf("RevertRestore() error = %v", err)
+	}
+	files, err := s.List()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("List() = %v, %v", files, err)
+	}
+	after := files[0]
+	if after.ID != before.ID || after.RunID != before.RunID || after.TrashPath != before.TrashPath {
+		t.Errorf("reverted file = %+v, want %+v", after, before)
+	}
+	if _, err := os.Lstat(after.TrashPath); err != nil {
+		t.Errorf("file should be back in the trash: %v", err)
+	}
+}
+
 func TestStorage_Remove(t *testing.T) {
 	dir := t.TempDir()
 	cfg := newTestConfig(dir)
@@ -187,6 +269,40 @@ func TestStorage_Remove(t *testing.T) {
 	}
 }
 
+func TestStorage_PutFile(t *testing.T) {
+	s, err := NewStorage(newTestConfig(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srcFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(srcFile, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := s.(trash.Putter).PutFile(srcFile)
+	if err != nil {
+		t.Fatalf("PutFile() error = %v", err)
+	}
+	if file.OriginalPath != srcFile || file.Backend != trash.StorageTypeLegacy || file.RunID != "test-run" {
+		t.Errorf("PutFile() = %+v", file)
+	}
+	if _, err := os.Stat(file.TrashPath); err != nil {
+		t.Errorf("trashed file not found: %v", err)
+	}
+
+	files, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].TrashPath != file.TrashPath {
+		t.Errorf("List() = %v, want the file returned by PutFile", files)
+	}
+	if m := files[0].Metadata; m == nil || m.Perm() != file.Metadata.Perm() || !m.ModTime.Equal(file.Metadata.ModTime) {
+		t.Errorf("Metadata = %+v, want %+v", m, file.Metadata)
+	}
+}
+
 func TestStorage_PutDirectory(t *testing.T) {
 	dir := t.TempDir()
 	cfg := newTestConfig(dir)
@@ -296,3 +412,34 @@ func TestStorage_PutMultipleFiles(t *testing.T) {
 		t.Errorf("List() returned %d files, want 3", len(files))
 	}
 }
+
+func TestStorage_PlanPut(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(newTestConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(t.TempDir(), "plan.txt")
+	if err := os.WriteFile(src, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := s.(*Storage).PlanPut(src)
+	if err != nil {
+		t.Fatalf("PlanPut() error = %v", err)
+	}
+	if plan.Backend != trash.StorageTypeLegacy || plan.TrashDir != dir {
+		t.Errorf("PlanPut() = %+v, want legacy trash %s", plan, dir)
+	}
+	if !strings.HasPrefix(plan.TrashPath, dir) || filepath.Base(plan.TrashPath) != "plan.txt.<id>" {
+		t.Errorf("TrashPath = %q", plan.TrashPath)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("source should still exist: %v", err)
+	}
+	files, _ := s.List()
+	if len(files) != 0 {
+		t.Errorf("history should be empty, got %d files", len(files))
+	}
+}
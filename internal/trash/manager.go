@@ -37,6 +37,25 @@ type Trash interface {
 	Remove(file *File) error
 }
 
+// Putter is implemented by trashes and storages that tell where Put
+// moved a file
+type Putter interface {
+	PutFile(src string) (*File, error)
+}
+
+// Deleter is implemented by trashes that can permanently delete a file
+// in place of a put, running the hooks around it
+type Deleter interface {
+	Delete(src string) error
+}
+
+// Reverter is implemented by trashes and storages that can move a
+// restored file back into trash under its former name, keeping the
+// metadata it was trashed with, such as its run ID
+type Reverter interface {
+	RevertRestore(file *File, src string) error
+}
+
 // Manager handles multiple trash storage implementations
 type Manager struct {
 	storages []Storage
@@ -109,32 +128,56 @@ func determineStrategy(storages []Storage) Strategy {
 
 // Put moves the file at src path to trash
 func (m *Manager) Put(src string) error {
+	_, err := m.PutFile(src)
+	return err
+}
+
+// PutFile moves the file at src path to trash and returns the trashed
+// file. For storages that do not implement Putter, only the original
+// path and the backend are known.
+func (m *Manager) PutFile(src string) (*File, error) {
+	return m.putFile(src, m.storages)
+}
+
+// putFile moves src to the first of storages that takes it
+func (m *Manager) putFile(src string, storages []Storage) (*File, error) {
 	path, err := filepath.Abs(src)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
 	slog.Debug("putting file to trash",
 		"source", src,
 		"absolute_path", path,
 		"strategy", m.strategy,
-		"storages", len(m.storages))
+		"storages", len(storages))
 
 	fi, err := os.Lstat(path)
 	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if err := m.prePut(path, fi.IsDir(), storages); err != nil {
+		return nil, err
 	}
 
 	var lastErr error
-	for _, storage := range m.storages {
-		err := storage.Put(path)
+	for _, storage := range storages {
+		var file *File
+		if putter, ok := storage.(Putter); ok {
+			file, err = putter.PutFile(path)
+		} else {
+			file, err = &File{Name: filepath.Base(path), OriginalPath: path, IsDir: fi.IsDir()}, storage.Put(path)
+		}
 		if err == nil {
 			if fi.IsDir() {
 				slog.Debug("moved directory to trash", "path", path)
 			} else {
 				slog.Debug("moved file to trash", "path", path)
 			}
-			return nil
+			file.Backend = storage.Info().Type
+			_ = m.hook(Event{Name: EventPostPut, File: file})
+			return file, nil
 		}
 		lastErr = err
 		slog.Debug("storage failed to put file",
@@ -142,7 +185,7 @@ func (m *Manager) Put(src string) error {
 			"error", err)
 	}
 
-	return fmt.Errorf("all storage backends failed to put file: %w", lastErr)
+	return nil, fmt.Errorf("all storage backends failed to put file: %w", lastErr)
 }
 
 // List returns all files from all storage backends
@@ -163,6 +206,9 @@ func (m *Manager) List() ([]*File, error) {
 					s.Info().Trashes, err)}
 				return
 			}
+			for _, f := range files {
+				f.Backend = s.Info().Type
+			}
 			slog.Info("list files",
 				"storage_type", s.Info().Type,
 				"len(files)", len(files))
@@ -204,7 +250,14 @@ func (m *Manager) Restore(file *File, dst string) error {
 		return ErrFileExists
 	}
 
-	return storage.Restore(file, dst)
+	if err := m.hook(Event{Name: EventPreRestore, File: file, Dst: dst}); err != nil {
+		return err
+	}
+	if err := storage.Restore(file, dst); err != nil {
+		return err
+	}
+	_ = m.hook(Event{Name: EventPostRestore, File: file, Dst: dst})
+	return nil
 }
 
 // Remove permanently removes the file from trash
@@ -214,7 +267,54 @@ func (m *Manager) Remove(file *File) error {
 		return err
 	}
 
-	return storage.Remove(file)
+	if err := storage.Remove(file); err != nil {
+		return err
+	}
+	_ = m.hook(Event{Name: EventPostRemove, File: file})
+	return nil
+}
+
+// Delete permanently deletes the file at src instead of moving it to
+// trash. The pre_put hook can veto it as it would a put, with no trash
+// path, and post_remove runs once it is gone.
+func (m *Manager) Delete(src string) error {
+	path, err := filepath.Abs(src)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if err := m.prePut(path, fi.IsDir(), nil); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	_ = m.hook(Event{Name: EventPostRemove, File: &File{Name: filepath.Base(path), OriginalPath: path, IsDir: fi.IsDir()}})
+	return nil
+}
+
+// RevertRestore moves src, where file was restored to, back into trash
+// as it was before. It undoes a restore rather than trashing the file
+// anew, so pre_put cannot veto it, but post_put runs so that hooks which
+// followed the restore see the file back in trash.
+func (m *Manager) RevertRestore(file *File, src string) error {
+	storage, err := m.findStorageForFile(file)
+	if err != nil {
+		return err
+	}
+	reverter, ok := storage.(Reverter)
+	if !ok {
+		return fmt.Errorf("%s storage cannot revert a restore: %w", storage.Info().Type, errors.ErrUnsupported)
+	}
+	if err := reverter.RevertRestore(file, src); err != nil {
+		return err
+	}
+	_ = m.hook(Event{Name: EventPostPut, File: file})
+	return nil
 }
 
 // findStorageForFile returns the storage backend that manages the given file,
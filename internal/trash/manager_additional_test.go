@@ -96,6 +96,53 @@ func TestManager_Put(t *testing.T) {
 	})
 }
 
+// mockPutterStorage is a storage that tells where Put moved a file
+type mockPutterStorage struct {
+	mockStorage
+}
+
+func (m *mockPutterStorage) PutFile(src string) (*File, error) {
+	if m.putErr != nil {
+		return nil, m.putErr
+	}
+	return &File{OriginalPath: src, TrashPath: "/trash/files/" + filepath.Base(src)}, nil
+}
+
+func TestManager_PutFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("putter storage", func(t *testing.T) {
+		m := &Manager{
+			storages: []Storage{
+				&mockPutterStorage{mockStorage{putErr: errors.New("fail"), storageType: StorageTypeXDG}},
+				&mockPutterStorage{mockStorage{storageType: StorageTypeLegacy}},
+			},
+		}
+		file, err := m.PutFile(testFile)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if file.TrashPath != "/trash/files/test.txt" || file.Backend != StorageTypeLegacy {
+			t.Errorf("PutFile() = %+v, want the file of the legacy storage", file)
+		}
+	})
+
+	t.Run("plain storage", func(t *testing.T) {
+		m := &Manager{storages: []Storage{&mockStorage{storageType: StorageTypeXDG}}}
+		file, err := m.PutFile(testFile)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if file.OriginalPath != testFile || file.TrashPath != "" || file.Backend != StorageTypeXDG {
+			t.Errorf("PutFile() = %+v, want only the original path and backend", file)
+		}
+	})
+}
+
 func TestManager_List(t *testing.T) {
 	t.Run("merges files from all storages", func(t *testing.T) {
 		m := &Manager{
@@ -119,6 +166,25 @@ func TestManager_List(t *testing.T) {
 		}
 	})
 
+	t.Run("tags files with their backend", func(t *testing.T) {
+		m := &Manager{
+			storages: []Storage{
+				&mockStorage{
+					storageType: StorageTypeLegacy,
+					files:       []*File{{Name: "a"}},
+					trashes:     []string{"/trash1"},
+				},
+			},
+		}
+		files, err := m.List()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 1 || files[0].Backend != StorageTypeLegacy {
+			t.Errorf("got %v, want one file with legacy backend", files)
+		}
+	})
+
 	t.Run("partial failure returns files from healthy storage", func(t *testing.T) {
 		m := &Manager{
 			storages: []Storage{
@@ -0,0 +1,155 @@
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+
+	"github.com/babarot/gomi/internal/utils/fs"
+)
+
+// maxCommandLen caps the recorded command line, which is stored with
+// every file of a batch
+const maxCommandLen = 512
+
+// Metadata holds details recorded when a file was moved to trash: its
+// original attributes and the gomi invocation that moved it. It is nil
+// for files trashed by other tools or older versions of gomi.
+type Metadata struct {
+	// Mode holds the original permission bits, including the setuid,
+	// setgid and sticky bits. It is stored in octal, see Perm.
+	Mode os.FileMode `json:"-"`
+
+	// UID and GID owned the file. They are -1 where unknown.
+	UID int `json:"uid"`
+	GID int `json:"gid"`
+
+	// ModTime and AccessTime are the original modification and access
+	// times, zero where unknown
+	ModTime    time.Time `json:"mtime,omitzero"`
+	AccessTime time.Time `json:"atime,omitzero"`
+
+	// Xattrs reports whether the file had extended attributes
+	Xattrs bool `json:"xattrs,omitempty"`
+
+	// Hostname, Cwd and Command describe the gomi invocation
+	Hostname string `json:"hostname,omitempty"`
+	Cwd      string `json:"cwd,omitempty"`
+	Command  string `json:"command,omitempty"`
+}
+
+// invocation returns the details of the running gomi shared by all files
+var invocation = sync.OnceValue(func() Metadata {
+	var m Metadata
+	m.Hostname, _ = os.Hostname()
+	m.Cwd, _ = os.Getwd()
+	m.Command = shellescape.QuoteCommand(os.Args)
+	if len(m.Command) > maxCommandLen {
+		m.Command = strings.ToValidUTF8(m.Command[:maxCommandLen-3], "") + "..."
+	}
+	return m
+})
+
+// NewMetadata returns the metadata of the file at path, to be called
+// before the file is moved. It returns nil if path cannot be read.
+func NewMetadata(path string) *Metadata {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+	m := invocation()
+	m.Mode = info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	m.ModTime = info.ModTime()
+
+	attrs, err := fs.ReadAttrs(path)
+	if err != nil {
+		slog.Debug("failed to read file attributes", "path", path, "error", err)
+	}
+	m.UID, m.GID = attrs.UID, attrs.GID
+	m.AccessTime = attrs.AccessTime
+	m.Xattrs = attrs.Xattrs
+	return &m
+}
+
+// Perm returns the mode in octal as chmod takes it, e.g. "0644"
+func (m *Metadata) Perm() string {
+	perm := uint32(m.Mode.Perm())
+	if m.Mode&os.ModeSetuid != 0 {
+		perm |= 0o4000
+	}
+	if m.Mode&os.ModeSetgid != 0 {
+		perm |= 0o2000
+	}
+	if m.Mode&os.ModeSticky != 0 {
+		perm |= 0o1000
+	}
+	return fmt.Sprintf("%04o", perm)
+}
+
+// ParsePerm returns the mode of an octal string returned by Perm
+func ParsePerm(s string) (os.FileMode, error) {
+	perm, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if perm > 0o7777 {
+		return 0, fmt.Errorf("invalid mode: %s", s)
+	}
+	mode := os.FileMode(perm & 0o777)
+	if perm&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if perm&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if perm&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// MarshalJSON stores the mode in octal, as Perm returns it
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	type metadata Metadata
+	return json.Marshal(struct {
+		Mode string `json:"mode"`
+		metadata
+	}{m.Perm(), metadata(m)})
+}
+
+// UnmarshalJSON reads metadata stored by MarshalJSON. An invalid mode is
+// ignored, as it is not needed to restore the file.
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	type metadata Metadata
+	aux := struct {
+		Mode json.RawMessage `json:"mode"`
+		*metadata
+	}{metadata: (*metadata)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var perm string
+	if err := json.Unmarshal(aux.Mode, &perm); err == nil {
+		if mode, err := ParsePerm(perm); err == nil {
+			m.Mode = mode
+			return nil
+		}
+	}
+	slog.Debug("ignoring invalid mode in metadata", "mode", string(aux.Mode))
+	return nil
+}
+
+// Owner returns "uid:gid", or "" where unknown
+func (m *Metadata) Owner() string {
+	if m.UID < 0 || m.GID < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", m.UID, m.GID)
+}
@@ -0,0 +1,121 @@
+package trash
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadata_Perm(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want string
+	}{
+		{0644, "0644"},
+		{0755 | os.ModeSetuid, "4755"},
+		{0775 | os.ModeSetgid, "2775"},
+		{0777 | os.ModeSticky, "1777"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			m := &Metadata{Mode: tt.mode}
+			if got := m.Perm(); got != tt.want {
+				t.Errorf("Perm() = %q, want %q", got, tt.want)
+			}
+			mode, err := ParsePerm(tt.want)
+			if err != nil || mode != tt.mode {
+				t.Errorf("ParsePerm(%q) = %v, %v, want %v", tt.want, mode, err, tt.mode)
+			}
+		})
+	}
+
+	for _, s := range []string{"", "rw-", "0999", "17777"} {
+		if _, err := ParsePerm(s); err == nil {
+			t.Errorf("ParsePerm(%q) should fail", s)
+		}
+	}
+}
+
+func TestNewMetadata(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+	path := filepath.Join(t.TempDir(), "a.sh")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0750); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMetadata(path)
+	if m == nil {
+		t.Fatal("NewMetadata() = nil")
+	}
+	if m.Perm() != "0750" {
+		t.Errorf("Perm() = %q, want 0750", m.Perm())
+	}
+	if m.UID != os.Getuid() || m.GID != os.Getgid() {
+		t.Errorf("owner = %s, want %d:%d", m.Owner(), os.Getuid(), os.Getgid())
+	}
+	if !m.ModTime.Equal(mtime) || !m.AccessTime.Equal(mtime) {
+		t.Errorf("times = %v, %v, want %v", m.ModTime, m.AccessTime, mtime)
+	}
+	if cwd, _ := os.Getwd(); m.Cwd != cwd || m.Command == "" {
+		t.Errorf("invocation = %q in %q, want the test command in %q", m.Command, m.Cwd, cwd)
+	}
+
+	if NewMetadata(filepath.Join(t.TempDir(), "missing")) != nil {
+		t.Error("NewMetadata() should be nil for a missing file")
+	}
+}
+
+func TestMetadata_Owner(t *testing.T) {
+	if got := (&Metadata{UID: 1000, GID: 100}).Owner(); got != "1000:100" {
+		t.Errorf("Owner() = %q, want 1000:100", got)
+	}
+	if got := (&Metadata{UID: -1, GID: -1}).Owner(); got != "" {
+		t.Errorf("Owner() = %q, want empty when unknown", got)
+	}
+}
+
+func TestMetadata_JSON(t *testing.T) {
+	want := Metadata{
+		Mode:     0755 | os.ModeSetuid,
+		UID:      1000,
+		GID:      100,
+		ModTime:  time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC),
+		Hostname: "host",
+		Command:  "gomi a.sh",
+	}
+	data, err := json.Marshal(&want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"mode":"4755"`) || strings.Contains(string(data), "atime") {
+		t.Errorf("json = %s, want an octal mode and no unknown times", data)
+	}
+
+	var got Metadata
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+
+	// The rest of the metadata is kept with an invalid mode
+	var invalid Metadata
+	if err := json.Unmarshal([]byte(`{"mode":420,"uid":1}`), &invalid); err != nil || invalid.Mode != 0 || invalid.UID != 1 {
+		t.Errorf("decoded %+v, %v, want the mode ignored", invalid, err)
+	}
+}
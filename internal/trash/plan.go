@@ -0,0 +1,73 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrPlanUnsupported is returned when no storage can plan a Put
+var ErrPlanUnsupported = errors.New("no storage backend supports dry run")
+
+// Plan describes what Put would do with a file
+type Plan struct {
+	// Path is the absolute path of the file to trash
+	Path string
+
+	// Backend is the storage that would take the file
+	Backend StorageType
+
+	// TrashDir is the root of the trash directory the file would go to
+	TrashDir string
+
+	// TrashPath is where the file would be moved. The final name may
+	// differ if another file with the same name is trashed first.
+	TrashPath string
+
+	// CrossDevice is true if the file would be copied to another device
+	// and then removed, instead of being renamed
+	CrossDevice bool
+
+	// CreateTrashDir is true if TrashDir would be created
+	CreateTrashDir bool
+}
+
+// Planner is implemented by storages that can tell what Put would do
+// without touching the filesystem
+type Planner interface {
+	PlanPut(src string) (*Plan, error)
+}
+
+// PlanPut returns what Put would do with src. Storages are tried in the
+// same order as in Put.
+func (m *Manager) PlanPut(src string) (*Plan, error) {
+	return m.planPut(src, m.storages)
+}
+
+// planPut returns what putFile would do with src
+func (m *Manager) planPut(src string, storages []Storage) (*Plan, error) {
+	path, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if _, err := os.Lstat(path); err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	lastErr := ErrPlanUnsupported
+	for _, storage := range storages {
+		planner, ok := storage.(Planner)
+		if !ok {
+			continue
+		}
+		plan, err := planner.PlanPut(path)
+		if err == nil {
+			return plan, nil
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("all storage backends failed to plan: %w", lastErr)
+}
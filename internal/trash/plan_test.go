@@ -0,0 +1,57 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mockPlannerStorage is a storage that returns a fixed plan
+type mockPlannerStorage struct {
+	mockStorage
+	planErr error
+}
+
+func (m *mockPlannerStorage) PlanPut(src string) (*Plan, error) {
+	if m.planErr != nil {
+		return nil, m.planErr
+	}
+	return &Plan{Path: src, Backend: m.storageType}, nil
+}
+
+func TestManager_PlanPut(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("first storage that can plan", func(t *testing.T) {
+		m := &Manager{storages: []Storage{
+			&mockStorage{storageType: StorageTypeXDG}, // not a Planner
+			&mockPlannerStorage{mockStorage: mockStorage{storageType: StorageTypeXDG}, planErr: errors.New("fail")},
+			&mockPlannerStorage{mockStorage: mockStorage{storageType: StorageTypeLegacy}},
+		}}
+		plan, err := m.PlanPut(src)
+		if err != nil {
+			t.Fatalf("PlanPut() error = %v", err)
+		}
+		if plan.Backend != StorageTypeLegacy || plan.Path != src {
+			t.Errorf("PlanPut() = %+v", plan)
+		}
+	})
+
+	t.Run("no planner", func(t *testing.T) {
+		m := &Manager{storages: []Storage{&mockStorage{}}}
+		if _, err := m.PlanPut(src); !errors.Is(err, ErrPlanUnsupported) {
+			t.Errorf("PlanPut() error = %v, want %v", err, ErrPlanUnsupported)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		m := &Manager{storages: []Storage{&mockPlannerStorage{}}}
+		if _, err := m.PlanPut(src + ".none"); err == nil {
+			t.Error("PlanPut() should fail for a missing file")
+		}
+	})
+}
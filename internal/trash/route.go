@@ -0,0 +1,43 @@
+package trash
+
+import "fmt"
+
+// Router is implemented by trashes that can put a file into a given
+// storage, instead of the first one that takes it
+type Router interface {
+	PutFileTo(src string, backend StorageType) (*File, error)
+	PlanPutTo(src string, backend StorageType) (*Plan, error)
+}
+
+// PutFileTo moves src to trash like PutFile, using only the storages of
+// the given type
+func (m *Manager) PutFileTo(src string, backend StorageType) (*File, error) {
+	storages, err := m.storagesOf(backend)
+	if err != nil {
+		return nil, err
+	}
+	return m.putFile(src, storages)
+}
+
+// PlanPutTo returns what PutFileTo would do with src
+func (m *Manager) PlanPutTo(src string, backend StorageType) (*Plan, error) {
+	storages, err := m.storagesOf(backend)
+	if err != nil {
+		return nil, err
+	}
+	return m.planPut(src, storages)
+}
+
+// storagesOf returns the storages of the given type
+func (m *Manager) storagesOf(backend StorageType) ([]Storage, error) {
+	var storages []Storage
+	for _, storage := range m.storages {
+		if storage.Info().Type == backend {
+			storages = append(storages, storage)
+		}
+	}
+	if len(storages) == 0 {
+		return nil, fmt.Errorf("%w: %s storage is not in use", ErrStorageNotReady, backend)
+	}
+	return storages, nil
+}
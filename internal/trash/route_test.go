@@ -0,0 +1,57 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_PutFileTo(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		storages: []Storage{
+			&mockPutterStorage{mockStorage{storageType: StorageTypeXDG}},
+			&mockPutterStorage{mockStorage{storageType: StorageTypeLegacy}},
+		},
+	}
+
+	file, err := m.PutFileTo(src, StorageTypeLegacy)
+	if err != nil {
+		t.Fatalf("PutFileTo() error = %v", err)
+	}
+	if file.Backend != StorageTypeLegacy {
+		t.Errorf("PutFileTo() backend = %v, want %v", file.Backend, StorageTypeLegacy)
+	}
+
+	m.storages = m.storages[:1]
+	if _, err := m.PutFileTo(src, StorageTypeLegacy); !errors.Is(err, ErrStorageNotReady) {
+		t.Errorf("PutFileTo() error = %v, want %v", err, ErrStorageNotReady)
+	}
+}
+
+func TestManager_PlanPutTo(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		storages: []Storage{
+			&mockPlannerStorage{mockStorage: mockStorage{storageType: StorageTypeXDG}},
+			&mockPlannerStorage{mockStorage: mockStorage{storageType: StorageTypeLegacy}},
+		},
+	}
+
+	plan, err := m.PlanPutTo(src, StorageTypeLegacy)
+	if err != nil {
+		t.Fatalf("PlanPutTo() error = %v", err)
+	}
+	if plan.Backend != StorageTypeLegacy {
+		t.Errorf("PlanPutTo() backend = %v, want %v", plan.Backend, StorageTypeLegacy)
+	}
+}
@@ -60,6 +60,10 @@ type StorageInfo struct {
 
 // File represents a file in trash
 type File struct {
+	// ID identifies the file within its storage: the name under files/
+	// for XDG trashes, or the history ID for legacy storage
+	ID string
+
 	// Name is the original base name of the file
 	Name string
 
@@ -84,6 +88,22 @@ type File struct {
 	// MountRoot is the root path of the mount point containing this trash
 	// This is used to resolve relative paths in .trashinfo files
 	MountRoot string
+
+	// Backend is the type of storage holding this file
+	Backend StorageType
+
+	// RunID identifies the gomi invocation that trashed this file.
+	// It is empty for files trashed by other tools.
+	RunID string
+
+	// Metadata holds the details recorded when the file was trashed,
+	// or nil if none were
+	Metadata *Metadata
+
+	// UnknownOrigin reports that the original path is unknown, as for
+	// orphaned data adopted by --doctor. OriginalPath is then only a
+	// placeholder under the home directory or the top of the mount.
+	UnknownOrigin bool
 }
 
 func (f *File) GetName() string {
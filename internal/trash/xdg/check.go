@@ -0,0 +1,182 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+// Check audits the home trash and all known $topdir trashes. A location
+// that cannot be checked, such as a stale mount, is reported in the
+// returned error along with the issues of the others.
+// It implements trash.Checker.
+func (s *Storage) Check() ([]trash.Issue, error) {
+	var issues []trash.Issue
+	var errs []error
+	for _, loc := range s.locations() {
+		slog.Debug("checking trash location", "root", loc.root)
+		found, err := checkLocation(loc)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		issues = append(issues, found...)
+	}
+	return issues, errors.Join(errs...)
+}
+
+// checkLocation reports wrong permissions on files/ and info/, .trashinfo
+// files that cannot be parsed or whose data is gone, and data files
+// without .trashinfo
+func checkLocation(loc *trashLocation) ([]trash.Issue, error) {
+	var issues []trash.Issue
+
+	// According to XDG spec 1.0, files/ and info/ must not be readable by others
+	for _, dir := range []string{loc.filesDir, loc.infoDir} {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			return issues, fmt.Errorf("failed to stat %s: %w", dir, err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0700 {
+			issues = append(issues, trash.Issue{
+				Kind:   trash.IssuePermission,
+				Path:   dir,
+				Detail: fmt.Sprintf("mode is %04o, expected 0700", perm),
+				Fix:    func() error { return os.Chmod(dir, 0700) },
+			})
+		}
+	}
+
+	entries, err := os.ReadDir(loc.infoDir)
+	if err != nil {
+		return issues, fmt.Errorf("failed to read info directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), ".trashinfo") {
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), "._") {
+			// exclude mac resource fork
+			continue
+		}
+
+		infoPath := filepath.Join(loc.infoDir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".trashinfo")
+		dataPath := filepath.Join(loc.filesDir, name)
+		_, statErr := os.Lstat(dataPath)
+		hasData := statErr == nil
+
+		if _, err := loadTrashInfo(infoPath); err != nil {
+			issue := trash.Issue{
+				Kind:   trash.IssueInvalidMetadata,
+				Path:   infoPath,
+				Detail: err.Error(),
+				Fix:    func() error { return os.Remove(infoPath) },
+			}
+			if hasData {
+				// Replace the broken metadata so the data stays restorable
+				issue.Detail += " (will be re-created with unknown origin)"
+				issue.Fix = func() error {
+					if err := os.Remove(infoPath); err != nil {
+						return err
+					}
+					return adoptDataPath(loc.root, dataPath)
+				}
+			}
+			issues = append(issues, issue)
+			continue
+		}
+
+		if os.IsNotExist(statErr) {
+			issues = append(issues, trash.Issue{
+				Kind:   trash.IssueOrphanedMetadata,
+				Path:   infoPath,
+				Detail: "trashed file no longer exists",
+				Fix:    func() error { return os.Remove(infoPath) },
+			})
+		}
+	}
+
+	orphans, err := FindOrphanedDataFiles(loc.root)
+	if err != nil {
+		return issues, err
+	}
+	for _, o := range orphans {
+		issues = append(issues, trash.Issue{
+			Kind:   trash.IssueOrphanedData,
+			Path:   o.Path,
+			Detail: "no .trashinfo (will be adopted with unknown origin)",
+			Fix:    func() error { return AdoptOrphanedDataFile(o) },
+		})
+	}
+
+	return issues, nil
+}
+
+// adoptDataPath adopts a single data file under trashDir/files
+func adoptDataPath(trashDir, dataPath string) error {
+	fi, err := os.Lstat(dataPath)
+	if err != nil {
+		return err
+	}
+	return AdoptOrphanedDataFile(OrphanedDataFile{
+		Name:     filepath.Base(dataPath),
+		Path:     dataPath,
+		TrashDir: trashDir,
+		ModTime:  fi.ModTime(),
+	})
+}
+
+// Leftovers returns the entries of the trash directory dir that List
+// skips: data without valid metadata, and metadata that cannot be parsed
+// or whose data is gone. It implements trash.LeftoverFinder.
+func (s *Storage) Leftovers(dir string) ([]string, error) {
+	for _, loc := range s.locations() {
+		if loc.root == dir {
+			return findLeftovers(loc)
+		}
+	}
+	return nil, nil
+}
+
+// findLeftovers returns the leftovers of a trash location
+func findLeftovers(loc *trashLocation) ([]string, error) {
+	var leftovers []string
+
+	// Names listed by List, as they have valid metadata and data
+	listed := make(map[string]bool)
+	infos, err := os.ReadDir(loc.infoDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read info directory: %w", err)
+	}
+	for _, entry := range infos {
+		if !strings.HasSuffix(entry.Name(), ".trashinfo") {
+			continue
+		}
+		infoPath := filepath.Join(loc.infoDir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".trashinfo")
+		_, infoErr := loadTrashInfo(infoPath)
+		_, dataErr := os.Stat(filepath.Join(loc.filesDir, name))
+		if infoErr == nil && dataErr == nil {
+			listed[name] = true
+			continue
+		}
+		leftovers = append(leftovers, infoPath)
+	}
+
+	files, err := os.ReadDir(loc.filesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files directory: %w", err)
+	}
+	for _, entry := range files {
+		if !listed[entry.Name()] {
+			leftovers = append(leftovers, filepath.Join(loc.filesDir, entry.Name()))
+		}
+	}
+	return leftovers, nil
+}
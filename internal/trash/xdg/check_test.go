@@ -0,0 +1,149 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/babarot/gomi/internal/trash"
+)
+
+func TestStorage_Check(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	root := filepath.Join(dataDir, "Trash")
+	filesDir := filepath.Join(root, "files")
+	infoDir := filepath.Join(root, "info")
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	validInfo := "[Trash Info]\nPath=/tmp/file\nDeletionDate=2024-06-15T10:30:00\n"
+
+	// A healthy entry, plus one of each problem
+	writeFile(filepath.Join(filesDir, "ok.txt"), "x")
+	writeFile(filepath.Join(infoDir, "ok.txt.trashinfo"), validInfo)
+	writeFile(filepath.Join(infoDir, "gone.txt.trashinfo"), validInfo)
+	writeFile(filepath.Join(filesDir, "lost.txt"), "x")
+	writeFile(filepath.Join(filesDir, "broken.txt"), "x")
+	writeFile(filepath.Join(infoDir, "broken.txt.trashinfo"), "garbage\n")
+	if err := os.Chmod(filesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := s.(trash.Checker).Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	want := map[trash.IssueKind]string{
+		trash.IssuePermission:       filesDir,
+		trash.IssueOrphanedMetadata: filepath.Join(infoDir, "gone.txt.trashinfo"),
+		trash.IssueOrphanedData:     filepath.Join(filesDir, "lost.txt"),
+		trash.IssueInvalidMetadata:  filepath.Join(infoDir, "broken.txt.trashinfo"),
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Check() returned %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for _, issue := range issues {
+		if want[issue.Kind] != issue.Path {
+			t.Errorf("unexpected issue %v", issue)
+		}
+		if issue.Fix == nil {
+			t.Fatalf("issue %v should be fixable", issue)
+		}
+		if err := issue.Fix(); err != nil {
+			t.Fatalf("Fix() for %v error = %v", issue, err)
+		}
+	}
+
+	issues, err = s.(trash.Checker).Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Check() after fix returned %v, want none", issues)
+	}
+
+	// Both data files stay restorable
+	files, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("List() returned %d files after fix, want 3", len(files))
+	}
+}
+
+func TestStorage_CheckUnreadableLocation(t *testing.T) {
+	s, _ := newTestStorage(t)
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "stale", ".Trash-1000")
+	root := filepath.Join(dir, "mnt", ".Trash-1000")
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(root, sub), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orphan := filepath.Join(root, "info", "gone.txt.trashinfo")
+	if err := os.WriteFile(orphan, []byte("[Trash Info]\nPath=gone.txt\nDeletionDate=2024-06-15T10:30:00\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s.(*Storage).externalTrashes = []*trashLocation{
+		newExternalLocation(missing, filepath.Dir(missing)),
+		newExternalLocation(root, filepath.Dir(root)),
+	}
+
+	issues, err := s.(trash.Checker).Check()
+	if err == nil {
+		t.Error("Check() should report the location that cannot be read")
+	}
+	if len(issues) != 1 || issues[0].Path != orphan {
+		t.Errorf("Check() = %v, want the issue of the readable location", issues)
+	}
+}
+
+func TestStorage_Leftovers(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	root := filepath.Join(dataDir, "Trash")
+
+	src := filepath.Join(t.TempDir(), "kept.txt")
+	if err := os.WriteFile(src, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(src); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(root, "files", "orphan.txt"):          "data without metadata",
+		filepath.Join(root, "files", "broken.txt"):          "data with invalid metadata",
+		filepath.Join(root, "info", "broken.txt.trashinfo"): "not a trashinfo",
+		filepath.Join(root, "info", "gone.txt.trashinfo"):   "[Trash Info]\nPath=/tmp/gone.txt\nDeletionDate=2024-06-15T10:30:00\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.(trash.LeftoverFinder).Leftovers(root)
+	if err != nil {
+		t.Fatalf("Leftovers() error = %v", err)
+	}
+	slices.Sort(got)
+	want := []string{
+		filepath.Join(root, "files", "broken.txt"),
+		filepath.Join(root, "files", "orphan.txt"),
+		filepath.Join(root, "info", "broken.txt.trashinfo"),
+		filepath.Join(root, "info", "gone.txt.trashinfo"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Leftovers() = %v\nwant %v", got, want)
+	}
+
+	if got, _ := s.(trash.LeftoverFinder).Leftovers("/not/a/trash"); len(got) != 0 {
+		t.Errorf("Leftovers() of an unknown directory = %v, want none", got)
+	}
+}
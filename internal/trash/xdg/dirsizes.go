@@ -0,0 +1,166 @@
+package xdg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// According to XDG spec 1.0, $trash/directorysizes caches the size of
+// trashed directories. Each line has the form:
+//
+//	[size in bytes] [mtime of .trashinfo in unix seconds] [percent-encoded name]
+//
+// The size is the disk usage of the directory, as "du -B1" reports it.
+const dirSizesFilename = "directorysizes"
+
+// dirSize is a single entry of the directorysizes cache
+type dirSize struct {
+	// Size is the disk usage of the directory in bytes
+	Size int64
+
+	// Mtime is the modification time of the corresponding .trashinfo file.
+	// An entry is stale when it no longer matches.
+	Mtime int64
+}
+
+// dirSizes maps directory names under $trash/files to their cached size
+type dirSizes map[string]dirSize
+
+// parseDirSizes reads the directorysizes format from a reader.
+// Malformed lines are skipped so that files written by other
+// implementations never make the whole cache unusable.
+func parseDirSizes(r io.Reader) (dirSizes, error) {
+	sizes := make(dirSizes)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		mtime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		name, err := url.PathUnescape(fields[2])
+		if err != nil {
+			continue
+		}
+
+		sizes[name] = dirSize{Size: size, Mtime: mtime}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading directorysizes: %w", err)
+	}
+
+	return sizes, nil
+}
+
+// loadDirSizes loads the directorysizes cache of a trash directory.
+// A missing file is treated as an empty cache.
+func loadDirSizes(root string) (dirSizes, error) {
+	f, err := os.Open(filepath.Join(root, dirSizesFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(dirSizes), nil
+		}
+		return nil, fmt.Errorf("failed to open directorysizes: %w", err)
+	}
+	defer f.Close()
+
+	return parseDirSizes(f)
+}
+
+// lookup returns the cached size if the entry is still valid for the given mtime
+func (d dirSizes) lookup(name string, mtime int64) (int64, bool) {
+	entry, ok := d[name]
+	if !ok || entry.Mtime != mtime {
+		return 0, false
+	}
+	return entry.Size, true
+}
+
+// save writes the cache atomically by renaming a temporary file,
+// as required by the spec so that readers never see a partial file
+func (d dirSizes) save(root string) error {
+	tmp, err := os.CreateTemp(root, "."+dirSizesFilename+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+	}
+
+	w := bufio.NewWriter(tmp)
+	for name, entry := range d {
+		fmt.Fprintf(w, "%d %d %s\n", entry.Size, entry.Mtime, encodeTrashPath(name))
+	}
+	if err := w.Flush(); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to write directorysizes: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(root, dirSizesFilename)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save directorysizes: %w", err)
+	}
+
+	return nil
+}
+
+// updateDirSizes applies update to the directorysizes cache of a trash
+// directory and saves it if anything changed. Entries of directories that
+// are no longer in the trash, e.g. removed by other tools, are dropped.
+func (s *Storage) updateDirSizes(root string, update func(dirSizes) bool) {
+	s.sizesMu.Lock()
+	defer s.sizesMu.Unlock()
+
+	sizes, err := loadDirSizes(root)
+	if err != nil {
+		slog.Warn("failed to load directorysizes", "root", root, "error", err)
+		return
+	}
+	changed := update(sizes)
+	for name := range sizes {
+		if _, err := os.Lstat(filepath.Join(root, "files", name)); os.IsNotExist(err) {
+			delete(sizes, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := sizes.save(root); err != nil {
+		slog.Warn("failed to save directorysizes", "root", root, "error", err)
+	}
+}
+
+// infoMtime returns the modification time of a .trashinfo file in unix seconds
+func infoMtime(infoPath string) (int64, error) {
+	fi, err := os.Stat(infoPath)
+	if err != nil {
+		return 0, err
+	}
+	return fi.ModTime().Unix(), nil
+}
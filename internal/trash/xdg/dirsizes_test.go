@@ -0,0 +1,201 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/babarot/gomi/internal/utils/fs"
+)
+
+func TestParseDirSizes(t *testing.T) {
+	input := strings.Join([]string{
+		"4096 1718447400 photos",
+		"123 1718447401 my%20dir",
+		"broken line",
+		"abc 1718447400 bad-size",
+		"",
+	}, "\n")
+
+	sizes, err := parseDirSizes(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseDirSizes() error = %v", err)
+	}
+	if len(sizes) != 2 {
+		t.Fatalf("parseDirSizes() returned %d entries, want 2", len(sizes))
+	}
+	if got := sizes["photos"]; got.Size != 4096 || got.Mtime != 1718447400 {
+		t.Errorf("photos = %+v, want {4096 1718447400}", got)
+	}
+	if _, ok := sizes["my dir"]; !ok {
+		t.Error("percent-encoded name should be decoded")
+	}
+}
+
+func TestDirSizes_SaveAndLoad(t *testing.T) {
+	root := t.TempDir()
+
+	sizes := dirSizes{
+		"plain":    {Size: 10, Mtime: 100},
+		"with sp+": {Size: 20, Mtime: 200},
+	}
+	if err := sizes.save(root); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, dirSizesFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "20 200 with%20sp%2B\n") {
+		t.Errorf("unexpected encoding in directorysizes:\n%s", data)
+	}
+
+	loaded, err := loadDirSizes(root)
+	if err != nil {
+		t.Fatalf("loadDirSizes() error = %v", err)
+	}
+	if len(loaded) != 2 || loaded["with sp+"] != sizes["with sp+"] {
+		t.Errorf("loadDirSizes() = %v, want %v", loaded, sizes)
+	}
+
+	// No temporary files should be left behind
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 {
+		t.Errorf("trash root has %d entries, want 1", len(entries))
+	}
+}
+
+func TestLoadDirSizes_Missing(t *testing.T) {
+	sizes, err := loadDirSizes(t.TempDir())
+	if err != nil {
+		t.Fatalf("loadDirSizes() error = %v", err)
+	}
+	if len(sizes) != 0 {
+		t.Errorf("loadDirSizes() returned %d entries, want 0", len(sizes))
+	}
+}
+
+func TestDirSizes_Lookup(t *testing.T) {
+	sizes := dirSizes{"dir": {Size: 42, Mtime: 100}}
+
+	if size, ok := sizes.lookup("dir", 100); !ok || size != 42 {
+		t.Errorf("lookup() = %d, %v, want 42, true", size, ok)
+	}
+	if _, ok := sizes.lookup("dir", 101); ok {
+		t.Error("lookup() should ignore stale entries")
+	}
+	if _, ok := sizes.lookup("missing", 100); ok {
+		t.Error("lookup() should miss unknown names")
+	}
+}
+
+func TestStorage_DirectorySizes(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	trashRoot := filepath.Join(dataDir, "Trash")
+
+	srcDir := filepath.Join(t.TempDir(), "bigdir")
+	if err := os.MkdirAll(filepath.Join(srcDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "sub", "b.txt"), []byte("123"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Put(srcDir); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	usage, err := fs.DiskUsage(filepath.Join(trashRoot, "files", "bigdir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := loadDirSizes(trashRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sizes["bigdir"].Size; got != usage {
+		t.Errorf("directorysizes entry = %d, want disk usage %d", got, usage)
+	}
+
+	files, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Size != usage {
+		t.Fatalf("List() size = %d, want %d", files[0].Size, usage)
+	}
+
+	if err := s.Remove(files[0]); err != nil {
+		t.Fatal(err)
+	}
+	sizes, err = loadDirSizes(trashRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sizes["bigdir"]; ok {
+		t.Error("directorysizes entry should be removed with the directory")
+	}
+}
+
+func TestStorage_List_ReadsDirectorySizesOnly(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	trashRoot := filepath.Join(dataDir, "Trash")
+
+	srcDir := filepath.Join(t.TempDir(), "dir")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "a.txt"), []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(srcDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate another implementation leaving a stale entry and an entry
+	// for a directory that is gone
+	stale := dirSizes{
+		"dir":  {Size: 999, Mtime: 1},
+		"gone": {Size: 1, Mtime: 1},
+	}
+	if err := stale.save(trashRoot); err != nil {
+		t.Fatal(err)
+	}
+	cachePath := filepath.Join(trashRoot, dirSizesFilename)
+	before, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Size != 0 {
+		t.Fatalf("List() size = %d, want 0 for a stale entry", files[0].Size)
+	}
+	after, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("List() should not write directorysizes:\n%s", after)
+	}
+
+	// The next change to the trash prunes the entry of the missing directory
+	if err := s.Remove(files[0]); err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := loadDirSizes(trashRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 0 {
+		t.Errorf("directorysizes = %v, want no entries", sizes)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +20,22 @@ const (
 	// According to XDG spec
 	trashInfoHeader = "[Trash Info]"
 	timeFormat      = "2006-01-02T15:04:05"
+
+	// Keys with the X-Gomi- prefix are gomi extensions.
+	// The spec allows additional keys and other implementations ignore them.
+	keyUnknownOrigin = "X-Gomi-UnknownOrigin"
+	keyRunID         = "X-Gomi-RunID"
+
+	// Metadata recorded at put time, see trash.Metadata
+	keyMode       = "X-Gomi-Mode"
+	keyUID        = "X-Gomi-UID"
+	keyGID        = "X-Gomi-GID"
+	keyModTime    = "X-Gomi-ModTime"
+	keyAccessTime = "X-Gomi-AccessTime"
+	keyXattrs     = "X-Gomi-Xattrs"
+	keyHostname   = "X-Gomi-Hostname"
+	keyCwd        = "X-Gomi-Cwd"
+	keyCommand    = "X-Gomi-Command"
 )
 
 // TrashInfo represents the contents of a .trashinfo file
@@ -35,6 +52,16 @@ type TrashInfo struct {
 	// MountRoot is the root path of the mount point containing this trash
 	// This is used to resolve relative paths
 	MountRoot string
+
+	// UnknownOrigin marks metadata that was synthesized for a data file
+	// found without .trashinfo, so Path is only a recovery location
+	UnknownOrigin bool
+
+	// RunID identifies the gomi invocation that trashed the file
+	RunID string
+
+	// Metadata holds the details recorded when the file was trashed
+	Metadata *trash.Metadata
 }
 
 // NewInfo creates a TrashInfo from a reader
@@ -86,6 +113,15 @@ func NewInfo(r io.Reader) (*TrashInfo, error) {
 				return nil, fmt.Errorf("invalid DeletionDate format: %w", err)
 			}
 			info.DeletionDate = date
+
+		case keyUnknownOrigin:
+			info.UnknownOrigin = value == "true"
+
+		case keyRunID:
+			info.RunID = value
+
+		case keyMode, keyUID, keyGID, keyModTime, keyAccessTime, keyXattrs, keyHostname, keyCwd, keyCommand:
+			info.parseMetadata(key, value)
 		}
 	}
 
@@ -140,6 +176,10 @@ func (i *TrashInfo) GetRelativePath() string {
 	if err != nil {
 		return i.Path
 	}
+	// Paths outside the mount root cannot be relative to $topdir
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return i.Path
+	}
 	return rel
 }
 
@@ -150,6 +190,13 @@ func (i *TrashInfo) Save(path string) error {
 	fmt.Fprintln(content, trashInfoHeader)
 	fmt.Fprintf(content, "Path=%s\n", encodeTrashPath(i.GetRelativePath()))
 	fmt.Fprintf(content, "DeletionDate=%s\n", i.DeletionDate.Format(timeFormat))
+	if i.UnknownOrigin {
+		fmt.Fprintf(content, "%s=true\n", keyUnknownOrigin)
+	}
+	if i.RunID != "" {
+		fmt.Fprintf(content, "%s=%s\n", keyRunID, i.RunID)
+	}
+	i.writeMetadata(content)
 
 	// Write atomically using O_EXCL flag to prevent overwriting existing files
 	f, err := fs.Create(path, 0600)
@@ -167,6 +214,83 @@ func (i *TrashInfo) Save(path string) error {
 	return nil
 }
 
+// parseMetadata sets the metadata field of key. Invalid values are
+// ignored, as they are not needed to restore the file.
+func (i *TrashInfo) parseMetadata(key, value string) {
+	if i.Metadata == nil {
+		i.Metadata = &trash.Metadata{UID: -1, GID: -1}
+	}
+	m := i.Metadata
+
+	var err error
+	switch key {
+	case keyMode:
+		err = setValid(&m.Mode)(trash.ParsePerm(value))
+	case keyUID:
+		err = setValid(&m.UID)(strconv.Atoi(value))
+	case keyGID:
+		err = setValid(&m.GID)(strconv.Atoi(value))
+	case keyModTime:
+		err = setValid(&m.ModTime)(time.Parse(time.RFC3339Nano, value))
+	case keyAccessTime:
+		err = setValid(&m.AccessTime)(time.Parse(time.RFC3339Nano, value))
+	case keyXattrs:
+		m.Xattrs = value == "true"
+	case keyHostname:
+		m.Hostname = value
+	case keyCwd:
+		err = setValid(&m.Cwd)(url.QueryUnescape(value))
+	case keyCommand:
+		err = setValid(&m.Command)(url.QueryUnescape(value))
+	}
+	if err != nil {
+		slog.Debug("ignoring invalid trash info key", "key", key, "value", value, "error", err)
+	}
+}
+
+// setValid returns a function that sets dst to a parsed value, unless
+// parsing failed
+func setValid[T any](dst *T) func(T, error) error {
+	return func(v T, err error) error {
+		if err == nil {
+			*dst = v
+		}
+		return err
+	}
+}
+
+// writeMetadata writes the metadata keys. Cwd and Command are encoded
+// like Path, as they may contain newlines.
+func (i *TrashInfo) writeMetadata(w io.Writer) {
+	m := i.Metadata
+	if m == nil {
+		return
+	}
+	fmt.Fprintf(w, "%s=%s\n", keyMode, m.Perm())
+	if m.UID >= 0 && m.GID >= 0 {
+		fmt.Fprintf(w, "%s=%d\n", keyUID, m.UID)
+		fmt.Fprintf(w, "%s=%d\n", keyGID, m.GID)
+	}
+	if !m.ModTime.IsZero() {
+		fmt.Fprintf(w, "%s=%s\n", keyModTime, m.ModTime.Format(time.RFC3339Nano))
+	}
+	if !m.AccessTime.IsZero() {
+		fmt.Fprintf(w, "%s=%s\n", keyAccessTime, m.AccessTime.Format(time.RFC3339Nano))
+	}
+	if m.Xattrs {
+		fmt.Fprintf(w, "%s=true\n", keyXattrs)
+	}
+	if m.Hostname != "" {
+		fmt.Fprintf(w, "%s=%s\n", keyHostname, m.Hostname)
+	}
+	if m.Cwd != "" {
+		fmt.Fprintf(w, "%s=%s\n", keyCwd, encodeTrashPath(m.Cwd))
+	}
+	if m.Command != "" {
+		fmt.Fprintf(w, "%s=%s\n", keyCommand, encodeTrashPath(m.Command))
+	}
+}
+
 // encodeTrashPath encodes a path according to the XDG specification:
 // - Forward slashes are not encoded
 // - Spaces are encoded as %20 (not +)
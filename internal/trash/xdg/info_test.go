@@ -48,6 +48,55 @@ func TestNewInfo(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "unknown origin marker",
+			input: "[Trash Info]\nPath=/tmp/file\nDeletionDate=2024-01-01T00:00:00\nX-Gomi-UnknownOrigin=true\n",
+			check: func(t *testing.T, info *TrashInfo) {
+				if !info.UnknownOrigin {
+					t.Error("UnknownOrigin should be true")
+				}
+			},
+		},
+		{
+			name:  "run id",
+			input: "[Trash Info]\nPath=/tmp/file\nDeletionDate=2024-01-01T00:00:00\nX-Gomi-RunID=cq1abc\n",
+			check: func(t *testing.T, info *TrashInfo) {
+				if info.RunID != "cq1abc" {
+					t.Errorf("RunID = %q, want %q", info.RunID, "cq1abc")
+				}
+			},
+		},
+		{
+			name: "metadata",
+			input: "[Trash Info]\nPath=/tmp/file\nDeletionDate=2024-01-01T00:00:00\n" +
+				"X-Gomi-Mode=4755\nX-Gomi-UID=1000\nX-Gomi-GID=100\n" +
+				"X-Gomi-ModTime=2023-12-31T10:00:00Z\nX-Gomi-Xattrs=true\n" +
+				"X-Gomi-Hostname=host\nX-Gomi-Cwd=/home/u/my%20dir\nX-Gomi-Command=gomi%20-rf%20%27a%0Ab%27\n",
+			check: func(t *testing.T, info *TrashInfo) {
+				m := info.Metadata
+				if m == nil {
+					t.Fatal("Metadata = nil")
+				}
+				if m.Perm() != "4755" || m.Owner() != "1000:100" || !m.Xattrs || m.Hostname != "host" {
+					t.Errorf("Metadata = %+v", m)
+				}
+				if !m.ModTime.Equal(time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC)) || !m.AccessTime.IsZero() {
+					t.Errorf("times = %v, %v", m.ModTime, m.AccessTime)
+				}
+				if m.Cwd != "/home/u/my dir" || m.Command != "gomi -rf 'a\nb'" {
+					t.Errorf("invocation = %q in %q", m.Command, m.Cwd)
+				}
+			},
+		},
+		{
+			name:  "invalid metadata",
+			input: "[Trash Info]\nPath=/tmp/file\nDeletionDate=2024-01-01T00:00:00\nX-Gomi-Mode=rwx\nX-Gomi-UID=root\n",
+			check: func(t *testing.T, info *TrashInfo) {
+				if info.Metadata == nil || info.Metadata.UID != -1 {
+					t.Errorf("invalid values should be ignored: %+v", info.Metadata)
+				}
+			},
+		},
 		{
 			name:    "missing header",
 			input:   "Path=/tmp/file\nDeletionDate=2024-01-01T00:00:00\n",
@@ -148,6 +197,7 @@ func TestTrashInfo_GetRelativePath(t *testing.T) {
 		{"no mount root", "/home/user/file.txt", "", "/home/user/file.txt"},
 		{"already relative", "file.txt", "/media/usb", "file.txt"},
 		{"absolute with mount root", "/media/usb/Documents/file.txt", "/media/usb", "Documents/file.txt"},
+		{"outside mount root", "/home/user/file.txt", "/media/usb", "/home/user/file.txt"},
 	}
 
 	for _, tt := range tests {
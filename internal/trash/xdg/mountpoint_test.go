@@ -3,10 +3,14 @@
 package xdg
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
 	"testing"
+
+	"github.com/babarot/gomi/internal/trash"
 )
 
 func TestIsOnSameDevice(t *testing.T) {
@@ -153,3 +157,104 @@ func TestGetMountPoints(t *testing.T) {
 		t.Error("mount points should include /")
 	}
 }
+
+func TestStorage_CreateExternalTrash(t *testing.T) {
+	topdir := t.TempDir()
+	s := &Storage{config: trash.Config{CreateExternalTrash: true}}
+
+	loc, err := s.createExternalTrash(topdir)
+	if err != nil {
+		t.Fatalf("createExternalTrash() error = %v", err)
+	}
+
+	wantRoot := filepath.Join(topdir, fmt.Sprintf(".Trash-%d", os.Getuid()))
+	if loc.root != wantRoot {
+		t.Errorf("root = %q, want %q", loc.root, wantRoot)
+	}
+	if loc.mountRoot != topdir {
+		t.Errorf("mountRoot = %q, want %q", loc.mountRoot, topdir)
+	}
+	if loc.isHome {
+		t.Error("isHome should be false")
+	}
+	fi, err := os.Stat(wantRoot)
+	if err != nil {
+		t.Fatalf("trash directory not created: %v", err)
+	}
+	if fi.Mode().Perm() != 0700 {
+		t.Errorf("permissions = %o, want 0700", fi.Mode().Perm())
+	}
+	if len(s.externalTrashes) != 1 {
+		t.Errorf("externalTrashes = %d, want 1", len(s.externalTrashes))
+	}
+}
+
+func TestStorage_CreateExternalTrash_Concurrent(t *testing.T) {
+	topdir := t.TempDir()
+	s := &Storage{config: trash.Config{CreateExternalTrash: true}}
+
+	// Puts create the directory outside s.mu; it is registered once
+	var wg sync.WaitGroup
+	locs := make([]*trashLocation, 8)
+	for i := range locs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loc, err := s.createExternalTrash(topdir)
+			if err != nil {
+				t.Errorf("createExternalTrash() error = %v", err)
+			}
+			locs[i] = loc
+		}()
+	}
+	wg.Wait()
+
+	if len(s.externalTrashes) != 1 {
+		t.Fatalf("externalTrashes = %d, want 1", len(s.externalTrashes))
+	}
+	for _, loc := range locs {
+		if loc != s.externalTrashes[0] {
+			t.Errorf("createExternalTrash() = %p, want the registered location %p", loc, s.externalTrashes[0])
+		}
+	}
+}
+
+func TestStorage_CreateExternalTrash_NotAllowed(t *testing.T) {
+	topdir := t.TempDir()
+	s := &Storage{config: trash.Config{
+		CreateExternalTrash: true,
+		ExternalMounts:      []string{"/media/*"},
+	}}
+
+	if _, err := s.createExternalTrash(topdir); err == nil {
+		t.Fatal("createExternalTrash() should fail for a mount that is not allowed")
+	}
+	if _, err := os.Stat(filepath.Join(topdir, fmt.Sprintf(".Trash-%d", os.Getuid()))); !os.IsNotExist(err) {
+		t.Error("trash directory should not be created")
+	}
+}
+
+func TestStorage_IsMountAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		topdir   string
+		want     bool
+	}{
+		{"no patterns", nil, "/mnt/data", true},
+		{"exact match", []string{"/mnt/data"}, "/mnt/data", true},
+		{"glob match", []string{"/media/*"}, "/media/usb", true},
+		{"glob does not cross separator", []string{"/media/*"}, "/media/user/usb", false},
+		{"super glob", []string{"/media/**"}, "/media/user/usb", true},
+		{"no match", []string{"/media/*", "/run/media/*"}, "/mnt/data", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Storage{config: trash.Config{ExternalMounts: tt.patterns}}
+			if got := s.isMountAllowed(tt.topdir); got != tt.want {
+				t.Errorf("isMountAllowed(%q) = %v, want %v", tt.topdir, got, tt.want)
+			}
+		})
+	}
+}
@@ -94,3 +94,105 @@ func ParseTrashInfoFile(path string) (OrphanedFile, error) {
 		TrashInfoName: filepath.Base(path),
 	}, nil
 }
+
+// OrphanedDataFile represents a file in the trash that has no .trashinfo,
+// typically left behind by a crash or by another tool
+type OrphanedDataFile struct {
+	// Name is the file name under files/
+	Name string
+
+	// Path is the absolute path of the data file
+	Path string
+
+	// TrashDir is the trash directory containing the file
+	TrashDir string
+
+	// ModTime is the modification time of the data file
+	ModTime time.Time
+}
+
+func (o OrphanedDataFile) GetName() string         { return o.Name }
+func (o OrphanedDataFile) GetDeletedAt() time.Time { return o.ModTime }
+
+// FindOrphanedDataFiles finds files in the files directory without corresponding .trashinfo files
+func FindOrphanedDataFiles(trashDir string) ([]OrphanedDataFile, error) {
+	infoDir := filepath.Join(trashDir, "info")
+	filesDir := filepath.Join(trashDir, "files")
+
+	var orphanedFiles []OrphanedDataFile
+
+	entries, err := os.ReadDir(filesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "._") {
+			// exclude mac resource fork
+			slog.Debug("skipped mac resource fork", "path", entry.Name())
+			continue
+		}
+
+		_, err := os.Lstat(filepath.Join(infoDir, entry.Name()+".trashinfo"))
+		if !os.IsNotExist(err) {
+			continue
+		}
+
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		orphanedFiles = append(orphanedFiles, OrphanedDataFile{
+			Name:     entry.Name(),
+			Path:     filepath.Join(filesDir, entry.Name()),
+			TrashDir: trashDir,
+			ModTime:  fi.ModTime(),
+		})
+	}
+
+	return orphanedFiles, nil
+}
+
+// AdoptOrphanedDataFile writes a synthetic .trashinfo for a data file so that it
+// can be restored or purged like any other trashed file. Since the original
+// location is unknown, the file's mtime is used as the deletion date and the
+// metadata is marked with X-Gomi-UnknownOrigin. The recorded path points to
+// the top directory of the mount for $topdir trashes, or the home directory.
+func AdoptOrphanedDataFile(o OrphanedDataFile) error {
+	info := &TrashInfo{
+		DeletionDate:  o.ModTime,
+		UnknownOrigin: true,
+	}
+
+	if topdir := topDirOfTrash(o.TrashDir); topdir != "" {
+		info.Path = filepath.Join(topdir, o.Name)
+		info.MountRoot = topdir
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		info.Path = filepath.Join(home, o.Name)
+	}
+
+	infoPath := filepath.Join(o.TrashDir, "info", o.Name+".trashinfo")
+	if err := info.Save(infoPath); err != nil {
+		return fmt.Errorf("failed to save trash info: %w", err)
+	}
+
+	slog.Debug("adopted orphaned data file", "path", o.Path, "info", infoPath)
+	return nil
+}
+
+// topDirOfTrash returns $topdir for $topdir/.Trash/$uid and $topdir/.Trash-$uid
+// trash directories, or an empty string for the home trash
+func topDirOfTrash(trashDir string) string {
+	parent := filepath.Dir(trashDir)
+	switch {
+	case filepath.Base(parent) == ".Trash":
+		return filepath.Dir(parent)
+	case strings.HasPrefix(filepath.Base(trashDir), ".Trash-"):
+		return parent
+	}
+	return ""
+}
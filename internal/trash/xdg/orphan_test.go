@@ -49,3 +49,105 @@ func TestParseTrashInfoFile_NonExistent(t *testing.T) {
 		t.Error("expected error for non-existent file")
 	}
 }
+
+func TestFindOrphanedDataFiles(t *testing.T) {
+	trashDir := t.TempDir()
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(trashDir, sub), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// One indexed file, one orphan, one mac resource fork
+	for _, name := range []string{"indexed.txt", "orphan.txt", "._orphan.txt"} {
+		if err := os.WriteFile(filepath.Join(trashDir, "files", name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	info := "[Trash Info]\nPath=/tmp/indexed.txt\nDeletionDate=2024-06-15T10:30:00\n"
+	if err := os.WriteFile(filepath.Join(trashDir, "info", "indexed.txt.trashinfo"), []byte(info), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := FindOrphanedDataFiles(trashDir)
+	if err != nil {
+		t.Fatalf("FindOrphanedDataFiles() error = %v", err)
+	}
+	if len(orphans) != 1 {
+		t.Fatalf("FindOrphanedDataFiles() returned %d files, want 1", len(orphans))
+	}
+	if orphans[0].Name != "orphan.txt" {
+		t.Errorf("Name = %q, want %q", orphans[0].Name, "orphan.txt")
+	}
+	if orphans[0].GetDeletedAt().IsZero() {
+		t.Error("ModTime should be set")
+	}
+}
+
+func TestAdoptOrphanedDataFile(t *testing.T) {
+	topdir := t.TempDir()
+	trashDir := filepath.Join(topdir, ".Trash-1000")
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(trashDir, sub), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dataPath := filepath.Join(trashDir, "files", "lost file.txt")
+	if err := os.WriteFile(dataPath, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := fixedTime()
+	if err := os.Chtimes(dataPath, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := FindOrphanedDataFiles(trashDir)
+	if err != nil || len(orphans) != 1 {
+		t.Fatalf("FindOrphanedDataFiles() = %v, %v", orphans, err)
+	}
+	if err := AdoptOrphanedDataFile(orphans[0]); err != nil {
+		t.Fatalf("AdoptOrphanedDataFile() error = %v", err)
+	}
+
+	info, err := loadTrashInfo(filepath.Join(trashDir, "info", "lost file.txt.trashinfo"))
+	if err != nil {
+		t.Fatalf("adopted trash info is invalid: %v", err)
+	}
+	if !info.UnknownOrigin {
+		t.Error("UnknownOrigin marker should be set")
+	}
+	if info.Path != "lost file.txt" {
+		t.Errorf("Path = %q, want relative path %q", info.Path, "lost file.txt")
+	}
+	if !info.DeletionDate.Equal(mtime) {
+		t.Errorf("DeletionDate = %v, want %v", info.DeletionDate, mtime)
+	}
+
+	// Adopted file is no longer an orphan
+	orphans, err = FindOrphanedDataFiles(trashDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("FindOrphanedDataFiles() returned %d files after adoption, want 0", len(orphans))
+	}
+}
+
+func TestTopDirOfTrash(t *testing.T) {
+	tests := []struct {
+		trashDir string
+		want     string
+	}{
+		{filepath.Join("/media", "usb", ".Trash-1000"), filepath.Join("/media", "usb")},
+		{filepath.Join("/media", "usb", ".Trash", "1000"), filepath.Join("/media", "usb")},
+		{filepath.Join("/home", "user", ".local", "share", "Trash"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.trashDir, func(t *testing.T) {
+			if got := topDirOfTrash(tt.trashDir); got != tt.want {
+				t.Errorf("topDirOfTrash(%q) = %q, want %q", tt.trashDir, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,31 @@
 package xdg
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/gobwas/glob"
+
 	"github.com/babarot/gomi/internal/trash"
 	"github.com/babarot/gomi/internal/utils/fs"
 )
 
 // Storage implements the trash.Storage interface for XDG trash specification
 type Storage struct {
+	// mu protects externalTrashes, which grows when Put creates a new
+	// $topdir/.Trash-$uid during concurrent calls
+	mu sync.Mutex
+
+	// sizesMu serializes read-modify-write of directorysizes files
+	sizesMu sync.Mutex
+
 	// Home trash location (~/.local/share/Trash)
 	homeTrash *trashLocation
 
@@ -68,7 +80,7 @@ func NewStorage(cfg trash.Config) (trash.Storage, error) {
 
 func (s *Storage) Info() *trash.StorageInfo {
 	trashes := []string{s.homeTrash.root}
-	for _, ext := range s.externalTrashes {
+	for _, ext := range s.locations()[1:] {
 		trashes = append(trashes, ext.root)
 	}
 	return &trash.StorageInfo{
@@ -80,59 +92,178 @@ func (s *Storage) Info() *trash.StorageInfo {
 }
 
 func (s *Storage) Put(src string) error {
+	_, err := s.PutFile(src)
+	return err
+}
+
+// PutFile moves src to trash and returns the trashed file.
+// It implements trash.Putter.
+func (s *Storage) PutFile(src string) (*trash.File, error) {
 	abs, err := filepath.Abs(src)
 	if err != nil {
-		return trash.NewStorageError("put", src, err)
+		return nil, trash.NewStorageError("put", src, err)
 	}
 
 	// Select appropriate trash location
-	loc, err := s.selectTrashLocation(abs)
+	loc, err := s.selectTrashLocation(abs, false)
 	if err != nil {
-		return trash.NewStorageError("put", src, err)
-	}
-
-	// Generate unique name in trash
-	baseName := filepath.Base(abs)
-	trashName := baseName
-	counter := 1
-
-	for {
-		infoPath := filepath.Join(loc.infoDir, trashName+".trashinfo")
-		filePath := filepath.Join(loc.filesDir, trashName)
-
-		// Check if name is already taken
-		_, errInfo := os.Stat(infoPath)
-		_, errFile := os.Stat(filePath)
-		if os.IsNotExist(errInfo) && os.IsNotExist(errFile) {
-			break
-		}
-
-		// Generate new name with counter
-		trashName = fmt.Sprintf("%s_%d", baseName, counter)
-		counter++
+		return nil, trash.NewStorageError("put", src, err)
 	}
 
-	// Create .trashinfo file first
+	// Create .trashinfo file first, which reserves the name in trash
 	info := &TrashInfo{
 		Path:         abs,
 		MountRoot:    loc.mountRoot,
 		DeletionDate: time.Now(),
+		RunID:        s.config.RunID,
+		Metadata:     trash.NewMetadata(abs),
 	}
 
-	infoPath := filepath.Join(loc.infoDir, trashName+".trashinfo")
-	if err := info.Save(infoPath); err != nil {
-		return trash.NewStorageError("put", src, fmt.Errorf("failed to save trash info: %w", err))
+	trashName, infoPath, err := reserveTrashName(loc, filepath.Base(abs), info)
+	if err != nil {
+		return nil, trash.NewStorageError("put", src, fmt.Errorf("failed to save trash info: %w", err))
 	}
 
 	// Move file to trash
 	dstPath := filepath.Join(loc.filesDir, trashName)
-	if err := fs.Move(abs, dstPath, s.config.HomeFallback); err != nil {
+	if err := fs.MoveWithProgress(abs, dstPath, s.config.HomeFallback, s.config.CopyProgress(abs)); err != nil {
 		// If move fails, clean up the .trashinfo file
 		os.Remove(infoPath)
-		return trash.NewStorageError("put", src, fmt.Errorf("failed to move file to trash: %w", err))
+		return nil, trash.NewStorageError("put", src, fmt.Errorf("failed to move file to trash: %w", err))
 	}
 
-	return nil
+	file := &trash.File{
+		ID:           trashName,
+		Name:         filepath.Base(abs),
+		OriginalPath: abs,
+		TrashPath:    dstPath,
+		DeletedAt:    info.DeletionDate,
+		MountRoot:    loc.mountRoot,
+		Backend:      trash.StorageTypeXDG,
+		RunID:        info.RunID,
+		Metadata:     info.Metadata,
+	}
+	if fi, err := os.Lstat(dstPath); err == nil {
+		file.IsDir = fi.IsDir()
+		file.FileMode = fi.Mode()
+		if fi.IsDir() {
+			// Cache the size of trashed directories
+			s.recordDirSize(loc.root, trashName, dstPath, infoPath)
+		} else {
+			file.Size = fi.Size()
+		}
+	}
+
+	return file, nil
+}
+
+// PlanPut returns what Put would do with src without touching the filesystem
+func (s *Storage) PlanPut(src string) (*trash.Plan, error) {
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return nil, trash.NewStorageError("plan", src, err)
+	}
+
+	loc, err := s.selectTrashLocation(abs, true)
+	if err != nil {
+		return nil, trash.NewStorageError("plan", src, err)
+	}
+
+	plan := &trash.Plan{
+		Path:     abs,
+		Backend:  trash.StorageTypeXDG,
+		TrashDir: loc.root,
+	}
+	if _, err := os.Stat(loc.root); os.IsNotExist(err) {
+		plan.CreateTrashDir = true
+	} else if same, err := isOnSameDevice(abs, loc.root); err == nil && !same {
+		plan.CrossDevice = true
+	}
+	plan.TrashPath = filepath.Join(loc.filesDir, freeTrashName(loc, filepath.Base(abs)))
+
+	return plan, nil
+}
+
+// freeTrashName returns the name reserveTrashName would pick if nothing
+// else were trashed in the meantime
+func freeTrashName(loc *trashLocation, baseName string) string {
+	for counter := 0; ; counter++ {
+		trashName := baseName
+		if counter > 0 {
+			trashName = fmt.Sprintf("%s_%d", baseName, counter)
+		}
+		_, infoErr := os.Lstat(filepath.Join(loc.infoDir, trashName+".trashinfo"))
+		_, dataErr := os.Lstat(filepath.Join(loc.filesDir, trashName))
+		if os.IsNotExist(infoErr) && os.IsNotExist(dataErr) {
+			return trashName
+		}
+	}
+}
+
+// reserveTrashName picks a unique name in the trash location and reserves it
+// by creating its .trashinfo file with O_EXCL, as the XDG spec requires.
+// Because creation is atomic, concurrent callers (in this process or in
+// other processes) can never be handed the same name.
+// It returns the reserved name and the path of the saved .trashinfo file.
+func reserveTrashName(loc *trashLocation, baseName string, info *TrashInfo) (string, string, error) {
+	for counter := 0; ; counter++ {
+		trashName := baseName
+		if counter > 0 {
+			trashName = fmt.Sprintf("%s_%d", baseName, counter)
+		}
+
+		infoPath := filepath.Join(loc.infoDir, trashName+".trashinfo")
+		if err := info.Save(infoPath); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				// Name is taken, try the next one
+				continue
+			}
+			return "", "", err
+		}
+
+		// A data file without metadata (e.g. left behind by a crash or
+		// another tool) must not be overwritten, so give the name back
+		if _, err := os.Lstat(filepath.Join(loc.filesDir, trashName)); !os.IsNotExist(err) {
+			os.Remove(infoPath)
+			continue
+		}
+
+		return trashName, infoPath, nil
+	}
+}
+
+// recordDirSize adds the size of a trashed directory to directorysizes
+func (s *Storage) recordDirSize(root, name, dirPath, infoPath string) {
+	mtime, err := infoMtime(infoPath)
+	if err != nil {
+		slog.Warn("failed to stat trash info", "path", infoPath, "error", err)
+		return
+	}
+	size, err := fs.DiskUsage(dirPath)
+	if err != nil {
+		slog.Warn("failed to calculate directory size", "path", dirPath, "error", err)
+		return
+	}
+	s.updateDirSizes(root, func(sizes dirSizes) bool {
+		sizes[name] = dirSize{Size: size, Mtime: mtime}
+		return true
+	})
+}
+
+// forgetDirSize drops the directorysizes entry of a file leaving the trash
+func (s *Storage) forgetDirSize(file *trash.File) {
+	if !file.IsDir {
+		return
+	}
+	root := filepath.Dir(filepath.Dir(file.TrashPath))
+	name := filepath.Base(file.TrashPath)
+	s.updateDirSizes(root, func(sizes dirSizes) bool {
+		if _, ok := sizes[name]; !ok {
+			return false
+		}
+		delete(sizes, name)
+		return true
+	})
 }
 
 func (s *Storage) List() ([]*trash.File, error) {
@@ -146,7 +277,7 @@ func (s *Storage) List() ([]*trash.File, error) {
 	files = append(files, homeFiles...)
 
 	// List files from external trashes
-	for _, loc := range s.externalTrashes {
+	for _, loc := range s.locations()[1:] {
 		slog.Debug("listing external trash",
 			"path", loc.root)
 		extFiles, err := s.listLocation(loc)
@@ -186,6 +317,46 @@ func (s *Storage) Restore(file *trash.File, dst string) error {
 		slog.Warn("failed to remove trash info", "error", err)
 	}
 
+	s.forgetDirSize(file)
+
+	return nil
+}
+
+// RevertRestore moves src back to the trash path of file and writes its
+// .trashinfo again, so that the file is listed as before it was restored.
+// It implements trash.Reverter.
+func (s *Storage) RevertRestore(file *trash.File, src string) error {
+	mountRoot := file.MountRoot
+	if mountRoot == "" {
+		mountRoot = topDirOfTrash(filepath.Dir(filepath.Dir(file.TrashPath)))
+	}
+	info := &TrashInfo{
+		Path:          file.OriginalPath,
+		MountRoot:     mountRoot,
+		DeletionDate:  file.DeletedAt,
+		UnknownOrigin: file.UnknownOrigin,
+		RunID:         file.RunID,
+		Metadata:      file.Metadata,
+	}
+
+	// Saving fails if the name was taken in the meantime
+	infoPath := infoPathForFile(file.TrashPath)
+	if err := info.Save(infoPath); err != nil {
+		return trash.NewStorageError("revert", src, err)
+	}
+	if _, err := os.Lstat(file.TrashPath); !os.IsNotExist(err) {
+		os.Remove(infoPath)
+		return trash.NewStorageError("revert", src, fmt.Errorf("%s: %w", file.TrashPath, trash.ErrFileExists))
+	}
+	if err := fs.Move(src, file.TrashPath, s.config.HomeFallback); err != nil {
+		os.Remove(infoPath)
+		return trash.NewStorageError("revert", src, err)
+	}
+
+	if file.IsDir {
+		root := filepath.Dir(filepath.Dir(file.TrashPath))
+		s.recordDirSize(root, filepath.Base(file.TrashPath), file.TrashPath, infoPath)
+	}
 	return nil
 }
 
@@ -200,6 +371,8 @@ func (s *Storage) Remove(file *trash.File) error {
 		slog.Warn("failed to remove trash info", "error", err)
 	}
 
+	s.forgetDirSize(file)
+
 	return nil
 }
 
@@ -236,6 +409,10 @@ func (s *Storage) initHomeTrash() (*trashLocation, error) {
 		mountRoot: "",
 	}
 
+	if s.config.ReadOnly {
+		return loc, nil
+	}
+
 	// Create directories if they don't exist
 	if err := os.MkdirAll(loc.filesDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create files directory: %w", err)
@@ -261,28 +438,14 @@ func (s *Storage) scanExternalTrashes() error {
 		// Check for $topdir/.Trash/$uid
 		trashPath := filepath.Join(mount, ".Trash", uidStr)
 		if isValidExternalTrash(trashPath) {
-			loc := &trashLocation{
-				root:      trashPath,
-				filesDir:  filepath.Join(trashPath, "files"),
-				infoDir:   filepath.Join(trashPath, "info"),
-				isHome:    false,
-				mountRoot: mount, // Set mount root for relative paths
-			}
-			s.externalTrashes = append(s.externalTrashes, loc)
+			s.externalTrashes = append(s.externalTrashes, newExternalLocation(trashPath, mount))
 			continue
 		}
 
 		// Check for $topdir/.Trash-$uid
 		trashPath = filepath.Join(mount, fmt.Sprintf(".Trash-%d", uid))
 		if isValidExternalTrash(trashPath) {
-			loc := &trashLocation{
-				root:      trashPath,
-				filesDir:  filepath.Join(trashPath, "files"),
-				infoDir:   filepath.Join(trashPath, "info"),
-				isHome:    false,
-				mountRoot: mount, // Set mount root for relative paths
-			}
-			s.externalTrashes = append(s.externalTrashes, loc)
+			s.externalTrashes = append(s.externalTrashes, newExternalLocation(trashPath, mount))
 		}
 	}
 
@@ -298,11 +461,22 @@ func (s *Storage) listLocation(loc *trashLocation) ([]*trash.File, error) {
 		return nil, fmt.Errorf("failed to read files directory: %w", err)
 	}
 
+	// Directory sizes are served from directorysizes when the cache is
+	// valid and unknown otherwise. Listing never writes the cache, which
+	// is maintained by Put, Restore and Remove.
+	sizes, err := loadDirSizes(loc.root)
+	if err != nil {
+		slog.Warn("failed to load directorysizes", "root", loc.root, "error", err)
+		sizes = make(dirSizes)
+	}
+
 	for _, entry := range entries {
 		// Load corresponding .trashinfo file
-		info, err := loadTrashInfo(filepath.Join(loc.infoDir, entry.Name()+".trashinfo"))
+		infoPath := filepath.Join(loc.infoDir, entry.Name()+".trashinfo")
+		info, err := loadTrashInfo(infoPath)
 		if err != nil {
-			// Skip files without valid info
+			// Skip files without valid info; gomi --doctor reports them
+			slog.Debug("skipped file without valid trashinfo", "path", infoPath, "error", err)
 			continue
 		}
 
@@ -320,14 +494,29 @@ func (s *Storage) listLocation(loc *trashLocation) ([]*trash.File, error) {
 			continue
 		}
 
+		size := fileInfo.Size()
+		if fileInfo.IsDir() {
+			size = 0 // unknown unless found in the cache
+			if mtime, err := infoMtime(infoPath); err == nil {
+				if cached, ok := sizes.lookup(entry.Name(), mtime); ok {
+					size = cached
+				}
+			}
+		}
+
 		file := &trash.File{
+			ID:           entry.Name(),
 			Name:         filepath.Base(origPath),
 			OriginalPath: origPath,
 			TrashPath:    filePath,
 			DeletedAt:    info.DeletionDate,
-			Size:         fileInfo.Size(),
+			Size:         size,
 			IsDir:        fileInfo.IsDir(),
 			FileMode:     fileInfo.Mode(),
+			RunID:        info.RunID,
+			Metadata:     info.Metadata,
+
+			UnknownOrigin: info.UnknownOrigin,
 		}
 		files = append(files, file)
 	}
@@ -335,21 +524,46 @@ func (s *Storage) listLocation(loc *trashLocation) ([]*trash.File, error) {
 	return files, nil
 }
 
-func (s *Storage) selectTrashLocation(path string) (*trashLocation, error) {
+// selectTrashLocation returns the trash directory a file is moved to.
+// With dryRun, a trash directory that would be created on the file's mount
+// is returned without creating it.
+func (s *Storage) selectTrashLocation(path string, dryRun bool) (*trashLocation, error) {
 	// Check if file is on the same device as home trash
 	sameDevice, err := isOnSameDevice(path, s.homeTrash.root)
 	if err == nil && sameDevice {
 		return s.homeTrash, nil
 	}
 
-	// Look for matching external trash
-	for _, ext := range s.externalTrashes {
+	// Look for matching external trash. Mounts are stat'ed without s.mu,
+	// so that a slow or hung mount does not hold up other Puts.
+	for _, ext := range s.locations()[1:] {
 		sameDevice, err := isOnSameDevice(path, ext.root)
 		if err == nil && sameDevice {
 			return ext, nil
 		}
 	}
 
+	// Create $topdir/.Trash-$uid so that the file can be renamed
+	// instead of copied across devices
+	if s.config.CreateExternalTrash && !s.config.ForceHomeTrash {
+		topdir, err := findTopDir(path)
+		if err == nil {
+			if dryRun {
+				if s.isMountAllowed(topdir) {
+					return newExternalLocation(externalTrashRoot(topdir), topdir), nil
+				}
+			} else {
+				loc, err := s.createExternalTrash(topdir)
+				if err == nil {
+					return loc, nil
+				}
+				slog.Debug("cannot create external trash", "topdir", topdir, "error", err)
+			}
+		} else {
+			slog.Debug("cannot find top directory", "path", path, "error", err)
+		}
+	}
+
 	// If home fallback is enabled, use home trash
 	if s.config.HomeFallback {
 		return s.homeTrash, nil
@@ -358,6 +572,114 @@ func (s *Storage) selectTrashLocation(path string) (*trashLocation, error) {
 	return nil, trash.ErrCrossDevice
 }
 
+// createExternalTrash creates $topdir/.Trash-$uid and registers it as an
+// external trash location, unless a concurrent Put already did
+func (s *Storage) createExternalTrash(topdir string) (*trashLocation, error) {
+	if !s.isMountAllowed(topdir) {
+		return nil, fmt.Errorf("mount point %s is not allowed by config", topdir)
+	}
+
+	root := externalTrashRoot(topdir)
+	if err := createTrashDir(root); err != nil {
+		return nil, err
+	}
+	if !isValidExternalTrash(root) {
+		return nil, fmt.Errorf("invalid trash directory: %s", root)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ext := range s.externalTrashes {
+		if ext.root == root {
+			return ext, nil
+		}
+	}
+	loc := newExternalLocation(root, topdir)
+	s.externalTrashes = append(s.externalTrashes, loc)
+	slog.Info("created external trash", "path", root)
+	return loc, nil
+}
+
+// externalTrashRoot returns the path of $topdir/.Trash-$uid
+func externalTrashRoot(topdir string) string {
+	return filepath.Join(topdir, fmt.Sprintf(".Trash-%d", os.Getuid()))
+}
+
+// isMountAllowed reports whether a trash directory may be created on the mount point
+func (s *Storage) isMountAllowed(topdir string) bool {
+	if len(s.config.ExternalMounts) == 0 {
+		return true
+	}
+	for _, pattern := range s.config.ExternalMounts {
+		g, err := glob.Compile(filepath.Clean(pattern), filepath.Separator)
+		if err != nil {
+			slog.Warn("invalid mount pattern", "pattern", pattern, "error", err)
+			continue
+		}
+		if g.Match(topdir) {
+			return true
+		}
+	}
+	return false
+}
+
+// locations returns a snapshot of all trash locations, home trash first
+func (s *Storage) locations() []*trashLocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*trashLocation{s.homeTrash}, s.externalTrashes...)
+}
+
+// newExternalLocation returns a trash location for a $topdir trash directory
+func newExternalLocation(root, mountRoot string) *trashLocation {
+	return &trashLocation{
+		root:      root,
+		filesDir:  filepath.Join(root, "files"),
+		infoDir:   filepath.Join(root, "info"),
+		isHome:    false,
+		mountRoot: mountRoot, // Set mount root for relative paths
+	}
+}
+
+// findTopDir returns the mount point ($topdir) of the device containing path.
+// Only writable mounts that can hold a trash directory are considered.
+func findTopDir(path string) (string, error) {
+	// Resolve the parent only, the file itself may be a symlink
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	mounts, err := getMountPoints()
+	if err != nil {
+		return "", err
+	}
+
+	var topdir string
+	for _, mount := range mounts {
+		rel, err := filepath.Rel(mount, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(mount) > len(topdir) {
+			topdir = mount
+		}
+	}
+	if topdir == "" {
+		return "", fmt.Errorf("no writable mount point found for %s", path)
+	}
+
+	sameDevice, err := isOnSameDevice(dir, topdir)
+	if err != nil {
+		return "", err
+	}
+	if !sameDevice {
+		return "", fmt.Errorf("mount point %s is not on the same device as %s", topdir, path)
+	}
+
+	return topdir, nil
+}
+
 func (s *Storage) filter(files []*trash.File) []*trash.File {
 	opts := trash.FilterOptions{
 		Include: s.config.History.Include,
@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,6 +30,7 @@ func newTestStorage(t *testing.T) (trash.Storage, string) {
 		HomeFallback:   true,
 		ForceHomeTrash: true, // skip external trash scan
 		History:        config.History{},
+		RunID:          "test-run",
 	}
 
 	s, err := NewStorage(cfg)
@@ -100,11 +104,47 @@ func TestStorage_PutAndList(t *testing.T) {
 	if files[0].Name != "hello.txt" {
 		t.Errorf("Name = %q, want %q", files[0].Name, "hello.txt")
 	}
+	if files[0].RunID != "test-run" {
+		t.Errorf("RunID = %q, want %q", files[0].RunID, "test-run")
+	}
 	if files[0].OriginalPath != srcFile {
 		t.Errorf("OriginalPath = %q, want %q", files[0].OriginalPath, srcFile)
 	}
 }
 
+func TestStorage_PutFile(t *testing.T) {
+	s, _ := newTestStorage(t)
+
+	srcFile := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(srcFile, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := s.(trash.Putter).PutFile(srcFile)
+	if err != nil {
+		t.Fatalf("PutFile() error = %v", err)
+	}
+	if file.OriginalPath != srcFile || file.Backend != trash.StorageTypeXDG || file.Size != 5 {
+		t.Errorf("PutFile() = %+v", file)
+	}
+	if filepath.Base(file.TrashPath) != "hello.txt" {
+		t.Errorf("TrashPath = %q, want it named hello.txt", file.TrashPath)
+	}
+	if file.Metadata == nil || file.Metadata.Perm() != "0644" {
+		t.Errorf("Metadata = %+v, want the original mode", file.Metadata)
+	}
+	files, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Metadata == nil || files[0].Metadata.Owner() != file.Metadata.Owner() {
+		t.Errorf("List() should read back the metadata: %+v", files)
+	}
+	if _, err := os.Stat(file.TrashPath); err != nil {
+		t.Errorf("trashed file not found: %v", err)
+	}
+}
+
 func TestStorage_Put_CreatesTrashInfo(t *testing.T) {
 	s, dataDir := newTestStorage(t)
 
@@ -207,6 +247,42 @@ func TestStorage_Restore(t *testing.T) {
 	}
 }
 
+func TestStorage_RevertRestore(t *testing.T) {
+	s, _ := newTestStorage(t)
+
+	srcFile := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(srcFile, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(srcFile); err != nil {
+		t.Fatal(err)
+	}
+	files, err := s.List()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("List() = %v, %v", files, err)
+	}
+	before := files[0]
+	if err := s.Restore(before, srcFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.(trash.Reverter).RevertRestore(before, srcFile); err != nil {
+		t.Fatalf("RevertRestore() error = %v", err)
+	}
+	if _, err := os.Lstat(srcFile); !os.IsNotExist(err) {
+		t.Error("file should be back in the trash")
+	}
+	files, err = s.List()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("List() = %v, %v", files, err)
+	}
+	after := files[0]
+	if after.ID != before.ID || after.RunID != before.RunID || after.OriginalPath != before.OriginalPath ||
+		after.DeletedAt.Unix() != before.DeletedAt.Unix() || after.Metadata == nil {
+		t.Errorf("reverted file = %+v, want %+v", after, before)
+	}
+}
+
 func TestStorage_Restore_CustomDst(t *testing.T) {
 	s, _ := newTestStorage(t)
 
@@ -348,6 +424,30 @@ func TestStorage_List_SkipsInvalidInfo(t *testing.T) {
 	}
 }
 
+func TestStorage_List_UnknownOrigin(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	trashDir := filepath.Join(dataDir, "Trash")
+
+	if err := os.WriteFile(filepath.Join(trashDir, "files", "lost.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	orphans, err := FindOrphanedDataFiles(trashDir)
+	if err != nil || len(orphans) != 1 {
+		t.Fatalf("FindOrphanedDataFiles() = %v, %v", orphans, err)
+	}
+	if err := AdoptOrphanedDataFile(orphans[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(files) != 1 || !files[0].UnknownOrigin {
+		t.Errorf("List() = %+v, want an adopted file of unknown origin", files)
+	}
+}
+
 func TestTrashInfo_Save_And_Load(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Unix-specific test")
@@ -359,6 +459,15 @@ func TestTrashInfo_Save_And_Load(t *testing.T) {
 	info := &TrashInfo{
 		Path:         "/home/user/test file.txt",
 		DeletionDate: fixedTime(),
+		Metadata: &trash.Metadata{
+			Mode:     0640,
+			UID:      1000,
+			GID:      100,
+			ModTime:  fixedTime().Add(-time.Hour),
+			Hostname: "host",
+			Cwd:      "/home/user/dir\nwith newline",
+			Command:  "gomi 'test file.txt'",
+		},
 	}
 
 	if err := info.Save(infoPath); err != nil {
@@ -376,6 +485,10 @@ func TestTrashInfo_Save_And_Load(t *testing.T) {
 	if !loaded.DeletionDate.Equal(info.DeletionDate) {
 		t.Errorf("DeletionDate = %v, want %v", loaded.DeletionDate, info.DeletionDate)
 	}
+	if m := loaded.Metadata; m == nil || m.Perm() != "0640" || m.Owner() != "1000:100" ||
+		!m.ModTime.Equal(info.Metadata.ModTime) || m.Cwd != info.Metadata.Cwd || m.Command != info.Metadata.Command {
+		t.Errorf("Metadata = %+v, want %+v", loaded.Metadata, info.Metadata)
+	}
 }
 
 func TestTrashInfo_Save_NoOverwrite(t *testing.T) {
@@ -402,3 +515,194 @@ func TestTrashInfo_Save_NoOverwrite(t *testing.T) {
 func fixedTime() time.Time {
 	return time.Date(2024, 6, 15, 10, 30, 0, 0, time.Local)
 }
+
+func TestStorage_Put_ConcurrentSameName(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+
+	const n = 64
+	srcFiles := make([]string, n)
+	for i := range n {
+		srcFiles[i] = filepath.Join(t.TempDir(), "same.txt")
+		if err := os.WriteFile(srcFiles[i], []byte(strconv.Itoa(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for _, src := range srcFiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.Put(src)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	assertTrashConsistent(t, filepath.Join(dataDir, "Trash"), srcFiles)
+}
+
+func TestStorage_Put_ConcurrentSameName_MultipleInstances(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("XDG trash is not used on Windows")
+	}
+
+	// Separate Storage instances share nothing in memory, like two
+	// gomi processes trashing into the same directory
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+	cfg := trash.Config{Strategy: trash.StrategyXDG, HomeFallback: true, ForceHomeTrash: true}
+
+	const instances, perInstance = 4, 16
+	var srcFiles []string
+	var wg sync.WaitGroup
+	errs := make(chan error, instances*perInstance)
+
+	for range instances {
+		s, err := NewStorage(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range perInstance {
+			src := filepath.Join(t.TempDir(), "same.txt")
+			if err := os.WriteFile(src, []byte(strconv.Itoa(len(srcFiles))), 0644); err != nil {
+				t.Fatal(err)
+			}
+			srcFiles = append(srcFiles, src)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- s.Put(src)
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	assertTrashConsistent(t, filepath.Join(dataDir, "Trash"), srcFiles)
+}
+
+func TestStorage_Put_DoesNotOverwriteOrphanedData(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	trashRoot := filepath.Join(dataDir, "Trash")
+
+	// A data file without .trashinfo already occupies the name
+	orphan := filepath.Join(trashRoot, "files", "taken.txt")
+	if err := os.WriteFile(orphan, []byte("orphan"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(t.TempDir(), "taken.txt")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(src); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	data, err := os.ReadFile(orphan)
+	if err != nil || string(data) != "orphan" {
+		t.Errorf("orphaned data file was overwritten: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(trashRoot, "info", "taken.txt.trashinfo")); !os.IsNotExist(err) {
+		t.Error("reservation for a taken name should be released")
+	}
+	if _, err := os.Stat(filepath.Join(trashRoot, "files", "taken.txt_1")); err != nil {
+		t.Errorf("file should be trashed under the next free name: %v", err)
+	}
+}
+
+// assertTrashConsistent checks that every source file ended up in the trash
+// under its own name, with metadata that points back to it
+func assertTrashConsistent(t *testing.T, trashRoot string, srcFiles []string) {
+	t.Helper()
+
+	infos, err := os.ReadDir(filepath.Join(trashRoot, "info"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != len(srcFiles) {
+		t.Fatalf("found %d .trashinfo files, want %d", len(infos), len(srcFiles))
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range infos {
+		info, err := loadTrashInfo(filepath.Join(trashRoot, "info", entry.Name()))
+		if err != nil {
+			t.Fatalf("corrupted trash info %s: %v", entry.Name(), err)
+		}
+		if seen[info.Path] {
+			t.Errorf("duplicate metadata for %s", info.Path)
+		}
+		seen[info.Path] = true
+
+		// The data file must be the one the metadata refers to
+		name := strings.TrimSuffix(entry.Name(), ".trashinfo")
+		data, err := os.ReadFile(filepath.Join(trashRoot, "files", name))
+		if err != nil {
+			t.Fatalf("missing data for %s: %v", entry.Name(), err)
+		}
+		i, _ := strconv.Atoi(string(data))
+		if srcFiles[i] != info.Path {
+			t.Errorf("%s: metadata points to %s, data came from %s", name, info.Path, srcFiles[i])
+		}
+	}
+}
+
+func TestStorage_PlanPut(t *testing.T) {
+	s, dataDir := newTestStorage(t)
+	trashDir := filepath.Join(dataDir, "Trash")
+
+	src := filepath.Join(t.TempDir(), "plan.txt")
+	if err := os.WriteFile(src, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := s.(*Storage).PlanPut(src)
+	if err != nil {
+		t.Fatalf("PlanPut() error = %v", err)
+	}
+	if plan.Backend != trash.StorageTypeXDG || plan.TrashDir != trashDir {
+		t.Errorf("PlanPut() = %+v, want xdg trash %s", plan, trashDir)
+	}
+	if want := filepath.Join(trashDir, "files", "plan.txt"); plan.TrashPath != want {
+		t.Errorf("TrashPath = %q, want %q", plan.TrashPath, want)
+	}
+	if plan.CreateTrashDir {
+		t.Error("home trash should not be created")
+	}
+
+	// Nothing may be touched
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("source should still exist: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(trashDir, "info")); len(entries) != 0 {
+		t.Errorf("no trashinfo should be written, found %d", len(entries))
+	}
+
+	// A taken name is skipped as Put would do
+	if err := s.Put(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = s.(*Storage).PlanPut(src)
+	if err != nil {
+		t.Fatalf("PlanPut() error = %v", err)
+	}
+	if want := filepath.Join(trashDir, "files", "plan.txt_1"); plan.TrashPath != want {
+		t.Errorf("TrashPath = %q, want %q", plan.TrashPath, want)
+	}
+}
@@ -92,6 +92,26 @@ func InputFilename(file *trash.File) (string, error) {
 	return m.Value(), nil
 }
 
+// InputDirectory asks for the directory to restore files into
+func InputDirectory() (string, error) {
+	m := input.New()
+	m.Prompt = "Directory to restore into:"
+	m.Placeholder = "~/restored"
+	m.Validate = validate.NewValidation().
+		MinLength(1, "min: 1 characters").
+		Build()
+
+	p := tea.NewProgram(&m)
+	if _, err := p.Run(); err != nil {
+		return "", err
+	}
+
+	if m.Canceled() {
+		return m.Value(), ErrInputCanceled
+	}
+	return m.Value(), nil
+}
+
 func onlySpecialChars(input string) bool {
 	for _, char := range input {
 		if char != '.' && char != '_' && char != '-' {
@@ -11,8 +11,11 @@ import (
 	_ "image/png"
 	"log/slog"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/babarot/gomi/internal/trash"
 	"github.com/babarot/gomi/internal/utils/fs"
@@ -38,10 +41,14 @@ type File struct {
 }
 
 func (f File) Description() string {
+	from := filepath.Dir(f.OriginalPath)
+	if f.UnknownOrigin {
+		from += " (unknown origin)"
+	}
 	return fmt.Sprintf("%s %s %s",
 		humanize.Time(f.DeletedAt),
 		bullet,
-		filepath.Dir(f.OriginalPath),
+		from,
 	)
 }
 
@@ -57,6 +64,11 @@ func (f File) FilterValue() string {
 }
 
 func (f File) Size() string {
+	// Use the size reported by the storage (e.g. from the XDG
+	// directorysizes cache), walking the tree only when unknown
+	if f.File.Size > 0 {
+		return humanize.Bytes(uint64(f.File.Size))
+	}
 	var sizeStr string
 	size, err := fs.DirSize(f.TrashPath)
 	if err != nil {
@@ -215,3 +227,60 @@ func (f File) previewImage(path string, maxWidth, maxHeight int) (string, error)
 
 	return ansiStr + info, nil
 }
+
+// metadataRows returns the labels and values of the metadata recorded
+// when the file was trashed, or nil if none was
+func (f File) metadataRows(formatDate func(time.Time) string) [][2]string {
+	m := f.Metadata
+	if m == nil {
+		return nil
+	}
+	mode := m.Mode
+	if f.IsDir {
+		mode |= os.ModeDir
+	}
+	unknown := func(s string) string {
+		if s == "" {
+			return "(unknown)"
+		}
+		return s
+	}
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return "(unknown)"
+		}
+		return formatDate(t)
+	}
+	xattrs := "no"
+	if m.Xattrs {
+		xattrs = "yes"
+	}
+	return [][2]string{
+		{"Mode", fmt.Sprintf("%s (%s)", m.Perm(), mode)},
+		{"Owner", unknown(ownerNames(m))},
+		{"Modified", date(m.ModTime)},
+		{"Accessed", date(m.AccessTime)},
+		{"Xattrs", xattrs},
+		{"Host", unknown(m.Hostname)},
+		{"Directory", unknown(m.Cwd)},
+		{"Command", unknown(m.Command)},
+	}
+}
+
+// ownerNames returns the owner as "user:group (uid:gid)", with the IDs
+// alone if they have no names here
+func ownerNames(m *trash.Metadata) string {
+	ids := m.Owner()
+	if ids == "" {
+		return ""
+	}
+	u, err := user.LookupId(strconv.Itoa(m.UID))
+	if err != nil {
+		return ids
+	}
+	group := strconv.Itoa(m.GID)
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return fmt.Sprintf("%s:%s (%s)", u.Username, group, ids)
+}
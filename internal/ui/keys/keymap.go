@@ -20,12 +20,13 @@ type Common struct {
 
 // List view specific keys
 type List struct {
-	Space    key.Binding
-	Esc      key.Binding
-	Select   key.Binding
-	DeSelect key.Binding
-	Enter    key.Binding
-	Delete   *key.Binding // Optional key based on configuration
+	Space     key.Binding
+	Esc       key.Binding
+	Select    key.Binding
+	DeSelect  key.Binding
+	Enter     key.Binding
+	RestoreTo key.Binding
+	Delete    *key.Binding // Optional key based on configuration
 }
 
 // Detail view specific keys
@@ -41,6 +42,7 @@ type Detail struct {
 	GotoTop      key.Binding
 	GotoBottom   key.Binding
 	AtSign       key.Binding
+	Metadata     key.Binding
 	Delete       *key.Binding // Optional key based on configuration
 }
 
@@ -120,6 +122,10 @@ func NewKeyMap(cfg KeyMapConfig) *KeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "restore"),
 		),
+		RestoreTo: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "restore to"),
+		),
 	}
 
 	// Initialize detail view keys
@@ -168,6 +174,10 @@ func NewKeyMap(cfg KeyMapConfig) *KeyMap {
 			key.WithKeys("@"),
 			key.WithHelp("@", "info"),
 		),
+		Metadata: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "metadata"),
+		),
 	}
 
 	// Add delete key if enabled
@@ -248,7 +258,7 @@ func (k KeyMap) AsListKeyMap() KeyMap {
 				DefaultKeyMapListGoToStart,
 				DefaultKeyMapListGoToEnd,
 			},
-			{k.List.Enter, k.List.Space, k.List.Esc, k.List.Select, k.List.DeSelect},
+			{k.List.Enter, k.List.RestoreTo, k.List.Space, k.List.Esc, k.List.Select, k.List.DeSelect},
 			{k.Common.Quit, DefaultKeyMapListCloseFullHelp},
 		}
 		if k.List.Delete != nil {
@@ -279,7 +289,7 @@ func (k KeyMap) AsDetailKeyMap() KeyMap {
 				k.Detail.HalfPageUp, k.Detail.HalfPageDown,
 				k.Detail.GotoTop, k.Detail.GotoBottom,
 			},
-			{k.Detail.AtSign, k.Common.Quit, DefaultKeyMapListCloseFullHelp},
+			{k.Detail.AtSign, k.Detail.Metadata, k.Common.Quit, DefaultKeyMapListCloseFullHelp},
 		}
 		if k.Detail.Delete != nil {
 			bindings[0] = append(bindings[0], *k.Detail.Delete)
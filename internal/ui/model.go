@@ -31,6 +31,9 @@ type Model struct {
 	files   []File
 	choices []File
 
+	// restoreTo is set when the choices go to another directory
+	restoreTo bool
+
 	// Selection tracking
 	selection *SelectionManager
 
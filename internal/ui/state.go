@@ -47,8 +47,9 @@ type ViewState struct {
 }
 
 type detail struct {
-	showOrigin bool
-	dateFormat DateFormat
+	showOrigin   bool
+	dateFormat   DateFormat
+	showMetadata bool
 }
 
 type preview struct {
@@ -106,6 +107,12 @@ func (v *ViewState) ToggleOriginPath() {
 	v.detail.showOrigin = !v.detail.showOrigin
 }
 
+// ToggleMetadata switches the preview pane between the file contents and
+// the metadata recorded when the file was trashed
+func (v *ViewState) ToggleMetadata() {
+	v.detail.showMetadata = !v.detail.showMetadata
+}
+
 // FormatDate formats the given time according to the current date format
 func (v *ViewState) FormatDate(t time.Time) string {
 	if v.detail.dateFormat == DateFormatAbsolute {
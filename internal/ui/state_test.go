@@ -76,6 +76,18 @@ func TestViewState_ToggleOriginPath(t *testing.T) {
 	}
 }
 
+func TestViewState_ToggleMetadata(t *testing.T) {
+	s := NewViewState()
+	if s.detail.showMetadata {
+		t.Fatal("showMetadata should be false by default")
+	}
+
+	s.ToggleMetadata()
+	if !s.detail.showMetadata {
+		t.Error("showMetadata should be true after toggle")
+	}
+}
+
 func TestViewState_FormatDate(t *testing.T) {
 	s := NewViewState()
 	now := time.Now()
@@ -55,6 +55,7 @@ type PreviewStyles struct {
 	Border lipgloss.Style
 	Size   lipgloss.Style
 	Scroll lipgloss.Style
+	Label  lipgloss.Style
 	Error  PreviewErrorStyles
 }
 
@@ -165,6 +166,8 @@ func New(cfg config.UI) *Styles {
 				Padding(0, 1, 0, 1).
 				Foreground(lipgloss.Color(cfg.Style.DetailView.PreviewPane.Scroll.Foreground)).
 				Background(lipgloss.Color(cfg.Style.DetailView.PreviewPane.Scroll.Background)),
+			Label: lipgloss.NewStyle().
+				Foreground(lipgloss.ANSIColor(termenv.ANSIBrightBlack)),
 			Error: PreviewErrorStyles{
 				Title: lipgloss.NewStyle().
 					Foreground(lipgloss.ANSIColor(termenv.ANSIBrightBlack)),
@@ -267,6 +270,31 @@ func (s *Styles) RenderErrorPreview(errorMsg, mime string, width, height int) st
 	)
 }
 
+// RenderMetadata renders rows of labels and values in a pane of the given
+// size, wrapping long values
+func (s *Styles) RenderMetadata(rows [][2]string, width, height int) string {
+	var labelWidth int
+	for _, row := range rows {
+		labelWidth = max(labelWidth, lipgloss.Width(row[0]))
+	}
+	label := s.Detail.Preview.Label.Width(labelWidth + 2)
+	value := lipgloss.NewStyle().Width(width - labelWidth - 4) // label gap (2) + padding (2)
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top,
+			label.Render(row[0]),
+			value.Render(row[1]),
+		))
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		Padding(1, 1).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
 func (s *Styles) RenderDeletedFrom(title, content string) string {
 	return s.Detail.Info.DeletedFrom.Section.Render(
 		lipgloss.JoinVertical(
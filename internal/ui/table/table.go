@@ -2,6 +2,7 @@ package table
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -12,7 +13,8 @@ import (
 )
 
 const (
-	timeFormat = "2006-01-02 15:04:05"
+	// TimeFormat is the layout used for deletion times in tables
+	TimeFormat = "2006-01-02 15:04:05"
 )
 
 type FileEntry interface {
@@ -47,9 +49,27 @@ func PrintFiles[T FileEntry](files []T, opts PrintOptions) {
 		}
 	})
 
+	var rows [][]string
+	for _, file := range sortedFiles {
+		deletedAt := file.GetDeletedAt().Format(TimeFormat)
+		if opts.ShowRelativeTime {
+			deletedAt += fmt.Sprintf("  (%s)", humanize.Time(file.GetDeletedAt()))
+		}
+		rows = append(rows, []string{
+			deletedAt,
+			file.GetName(),
+		})
+	}
+
+	Render(os.Stdout, []string{"Deleted At", "Path"}, rows)
+}
+
+// Render writes rows as a borderless table in the style shared by all
+// gomi commands. Every row must have as many columns as the header.
+func Render(w io.Writer, header []string, rows [][]string) {
 	// Initialize table
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Deleted At", "Path"})
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
 
 	// Configure table appearance
 	table.SetBorder(false)
@@ -63,22 +83,17 @@ func PrintFiles[T FileEntry](files []T, opts PrintOptions) {
 	// Set column colors
 	green := tablewriter.Colors{tablewriter.Bold, 92} // bright green (FgHiGreen)
 	white := tablewriter.Colors{tablewriter.Bold, 37} // white (FgWhite)
-	table.SetHeaderColor(green, green)
-	table.SetColumnColor(white, white)
+	headerColors := make([]tablewriter.Colors, len(header))
+	columnColors := make([]tablewriter.Colors, len(header))
+	for i := range header {
+		headerColors[i] = green
+		columnColors[i] = white
+	}
+	table.SetHeaderColor(headerColors...)
+	table.SetColumnColor(columnColors...)
 
 	// Add rows
-	for _, file := range sortedFiles {
-		deletedAt := file.GetDeletedAt().Format(timeFormat)
-		if opts.ShowRelativeTime {
-			deletedAt += fmt.Sprintf("  (%s)", humanize.Time(file.GetDeletedAt()))
-		}
-
-		row := []string{
-			deletedAt,
-			file.GetName(),
-		}
-		table.Append(row)
-	}
+	table.AppendBulk(rows)
 
 	// Add padding between columns
 	table.SetColumnSeparator(strings.Repeat(" ", 2))
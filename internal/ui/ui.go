@@ -43,6 +43,7 @@ const (
 // Common errors
 var (
 	ErrCannotPreview = errors.New("cannot preview")
+	ErrNoMetadata    = errors.New("no metadata")
 	ErrInputCanceled = errors.New("input is canceled")
 )
 
@@ -62,8 +63,17 @@ func (m Model) Init() tea.Cmd {
 	)
 }
 
+// Result holds what the user chose in the file selection interface
+type Result struct {
+	Files []*trash.File
+
+	// RestoreTo is true when the files should be restored
+	// into another directory instead of their original location
+	RestoreTo bool
+}
+
 // Render displays the file selection interface and returns the selected files
-func Render(t trash.Trash, files []*trash.File, opts RenderOptions) ([]*trash.File, error) {
+func Render(t trash.Trash, files []*trash.File, opts RenderOptions) (Result, error) {
 	// Create and initialize the model
 	m := NewModel(t, files, opts)
 
@@ -73,19 +83,19 @@ func Render(t trash.Trash, files []*trash.File, opts RenderOptions) ([]*trash.Fi
 	// Run the UI
 	result, err := p.Run()
 	if err != nil {
-		return nil, err
+		return Result{}, err
 	}
 
 	// Process results
 	finalModel, ok := result.(Model)
 	if !ok {
-		return nil, fmt.Errorf("unexpected model type: %T", result)
+		return Result{}, fmt.Errorf("unexpected model type: %T", result)
 	}
 	if finalModel.state.current == Quitting {
 		if msg := opts.Config.ExitMessage; msg != "" {
 			fmt.Println(msg)
 		}
-		return nil, nil
+		return Result{}, nil
 	}
 
 	// Convert UI files back to trash files
@@ -95,5 +105,5 @@ func Render(t trash.Trash, files []*trash.File, opts RenderOptions) ([]*trash.Fi
 		trashFiles[i] = file.File
 	}
 
-	return trashFiles, nil
+	return Result{Files: trashFiles, RestoreTo: finalModel.restoreTo}, nil
 }
@@ -158,8 +158,9 @@ func (m Model) updateListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// DO NOT RETURN HERE
 		// to allow to update default list navigation
 
-	case key.Matches(msg, m.keyMap.List.Enter):
+	case key.Matches(msg, m.keyMap.List.Enter, m.keyMap.List.RestoreTo):
 		if m.list.FilterState() != list.Filtering {
+			m.restoreTo = key.Matches(msg, m.keyMap.List.RestoreTo)
 			files := m.selection.items
 			if len(files) == 0 {
 				file, ok := m.list.SelectedItem().(File)
@@ -170,6 +171,7 @@ func (m Model) updateListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.choices = files
 			}
 			slog.Debug("key input: enter",
+				"restore_to", m.restoreTo,
 				"files",
 				strings.Join(lo.Map(m.choices, func(file File, _ int) string {
 					return file.OriginalPath
@@ -211,6 +213,10 @@ func (m Model) updateDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.state.ToggleOriginPath()
 		return m, nil
 
+	case key.Matches(msg, m.keyMap.Detail.Metadata):
+		m.state.ToggleMetadata()
+		return m, nil
+
 	case key.Matches(msg,
 		m.keyMap.Detail.PreviewUp,
 		m.keyMap.Detail.PreviewDown,
@@ -251,6 +257,7 @@ func (m Model) updateDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keyMap.Detail.Space):
 		m.state.detail.showOrigin = true
 		m.state.detail.dateFormat = DateFormatRelative
+		m.state.detail.showMetadata = false
 		m.state.SetView(ListView)
 		return m, nil
 
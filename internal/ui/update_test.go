@@ -2,6 +2,7 @@ package ui
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -271,6 +272,43 @@ func TestUpdate_DetailView_AtSignToggles(t *testing.T) {
 	}
 }
 
+func TestUpdate_DetailView_MetadataToggles(t *testing.T) {
+	m := newTestModel()
+	m.state.SetView(DetailView)
+	m.detailFile = File{File: &trash.File{
+		Name: "a.txt",
+		Metadata: &trash.Metadata{
+			Mode:     0644,
+			UID:      -1,
+			GID:      -1,
+			Hostname: "host",
+			Cwd:      "/home/u",
+			Command:  "gomi " + strings.Repeat("very-long-argument ", 50),
+		},
+	}}
+
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")}
+	updated, _ := m.Update(msg)
+	model := asModel(t, updated)
+	if !model.state.detail.showMetadata {
+		t.Fatal("showMetadata should have toggled")
+	}
+
+	// The metadata replaces the preview without changing the layout
+	pane := model.renderMetadata()
+	if got, want := lipgloss.Height(pane), defaultHeight-11-1; got != want {
+		t.Errorf("metadata pane height = %d, want %d", got, want)
+	}
+	if !strings.Contains(pane, "0644 (-rw-r--r--)") || !strings.Contains(pane, "host") {
+		t.Errorf("metadata pane = %q", pane)
+	}
+
+	model.detailFile.Metadata = nil
+	if pane := model.renderMetadata(); !strings.Contains(pane, strings.ToUpper(ErrNoMetadata.Error())) {
+		t.Errorf("pane without metadata = %q", pane)
+	}
+}
+
 func TestUpdate_ConfirmView_YesNo_No(t *testing.T) {
 	m := newTestModel()
 	// Reset global selection manager for test isolation
@@ -408,3 +446,26 @@ func TestUpdate_ShowDetailMsg(t *testing.T) {
 		t.Errorf("detailFile.Name = %q, want %q", model.detailFile.Name, "detail.txt")
 	}
 }
+
+func TestUpdate_ListView_RestoreTo(t *testing.T) {
+	m := newTestModel()
+	m.selection = &SelectionManager{items: []File{}}
+	m.state.SetView(ListView)
+
+	f := newTestFile("test.txt")
+	m.list.SetItems([]list.Item{f})
+
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")}
+	updated, cmd := m.Update(msg)
+	model := asModel(t, updated)
+
+	if len(model.choices) != 1 {
+		t.Errorf("choices = %d, want 1", len(model.choices))
+	}
+	if !model.restoreTo {
+		t.Error("restoreTo should be set")
+	}
+	if cmd == nil {
+		t.Error("should return tea.Quit cmd")
+	}
+}
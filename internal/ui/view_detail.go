@@ -35,6 +35,10 @@ func (m Model) renderHeader() string {
 func (m Model) renderDeletedFrom() string {
 	text := filepath.Dir(m.detailFile.OriginalPath)
 	title := "Deleted From"
+	if m.detailFile.UnknownOrigin {
+		// The recorded path is a placeholder for adopted orphaned data
+		title = "Deleted From (unknown)"
+	}
 	if !m.state.detail.showOrigin {
 		title = "Trash Path"
 		text = filepath.Dir(m.detailFile.TrashPath)
@@ -65,7 +69,10 @@ func (m Model) renderDeletedAt() string {
 func (m Model) renderPreview() string {
 	content := m.viewport.View()
 
-	if !m.state.preview.available {
+	switch {
+	case m.state.detail.showMetadata:
+		content = m.renderMetadata()
+	case !m.state.preview.available:
 		mtype, _ := mimetype.DetectFile(m.detailFile.TrashPath)
 		content = m.styles.RenderErrorPreview(
 			ErrCannotPreview.Error(),
@@ -82,6 +89,22 @@ func (m Model) renderPreview() string {
 	)
 }
 
+// renderMetadata renders the metadata recorded when the file was trashed
+// in place of the preview
+func (m Model) renderMetadata() string {
+	height := defaultHeight - 11 - 1 // info pane height (11) + preview border (1)
+	rows := m.detailFile.metadataRows(m.state.FormatDate)
+	if rows == nil {
+		return m.styles.RenderErrorPreview(
+			ErrNoMetadata.Error(),
+			"trashed by another tool or an older version",
+			defaultWidth,
+			height,
+		)
+	}
+	return m.styles.RenderMetadata(rows, defaultWidth, height)
+}
+
 // previewHeader renders the header of the preview section
 func (m Model) previewHeader() string {
 	return m.styles.RenderPreviewFrame(
@@ -93,7 +116,7 @@ func (m Model) previewHeader() string {
 
 // previewFooter renders the footer of the preview section
 func (m Model) previewFooter() string {
-	if !m.state.preview.available {
+	if !m.state.preview.available || m.state.detail.showMetadata {
 		return m.styles.RenderPreviewFrame(
 			"", // do not show percentage if cannot preview
 			false,
@@ -12,6 +12,10 @@ var (
 	// GOMI_LOG_PATH is the path to the log file, following XDG base directory spec.
 	GOMI_LOG_PATH string
 
+	// GOMI_RETENTION_PATH records when the retention policy was last
+	// enforced, in the gomi data directory.
+	GOMI_RETENTION_PATH string
+
 	once sync.Once
 )
 
@@ -24,18 +28,20 @@ func Init() {
 		os.Setenv("CLICOLOR_FORCE", "1")
 
 		// Follow https://specifications.freedesktop.org/basedir-spec/latest/
-		if e := os.Getenv("GOMI_LOG_PATH"); e == "" {
-			dataDir := os.Getenv("XDG_DATA_HOME")
-			if dataDir == "" {
-				homeDir, err := os.UserHomeDir()
-				if err != nil {
-					panic(err)
-				}
-				dataDir = filepath.Join(homeDir, defaultXDGDataDirname)
+		dataDir := os.Getenv("XDG_DATA_HOME")
+		if dataDir == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				panic(err)
 			}
+			dataDir = filepath.Join(homeDir, defaultXDGDataDirname)
+		}
+
+		if e := os.Getenv("GOMI_LOG_PATH"); e == "" {
 			GOMI_LOG_PATH = filepath.Join(dataDir, "gomi", "debug.log")
 		} else {
 			GOMI_LOG_PATH = e
 		}
+		GOMI_RETENTION_PATH = filepath.Join(dataDir, "gomi", "retention")
 	})
 }
@@ -2,6 +2,7 @@ package fs
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -20,6 +21,12 @@ func Create(path string, perm os.FileMode) (*os.File, error) {
 // If the move fails due to being on different devices and fallbackCopy is true,
 // it will fall back to copy and delete.
 func Move(src, dst string, fallbackCopy bool) error {
+	return MoveWithProgress(src, dst, fallbackCopy, nil)
+}
+
+// MoveWithProgress is Move that calls progress with the number of bytes
+// copied so far when it falls back to copying. progress may be nil.
+func MoveWithProgress(src, dst string, fallbackCopy bool, progress func(copied int64)) error {
 	// Ensure the destination directory exists
 	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
@@ -32,7 +39,16 @@ func Move(src, dst string, fallbackCopy bool) error {
 		}
 
 		// Fallback to copy and delete
-		if err := cp.Copy(src, dst); err != nil {
+		var opts []cp.Options
+		if progress != nil {
+			var copied int64
+			opts = append(opts, cp.Options{
+				WrapReader: func(r io.Reader) io.Reader {
+					return &progressReader{r: r, copied: &copied, progress: progress}
+				},
+			})
+		}
+		if err := cp.Copy(src, dst, opts...); err != nil {
 			return fmt.Errorf("failed to copy file: %w", err)
 		}
 
@@ -49,6 +65,22 @@ func Move(src, dst string, fallbackCopy bool) error {
 	return nil
 }
 
+// progressReader reports the bytes read from all the files of a copy
+type progressReader struct {
+	r        io.Reader
+	copied   *int64
+	progress func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		*p.copied += int64(n)
+		p.progress(*p.copied)
+	}
+	return n, err
+}
+
 // CreateWithBackup creates a new file while preserving the old one as a backup.
 // The backup will have the same name as the original with ".backup" appended.
 // Returns:
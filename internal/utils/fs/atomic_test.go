@@ -1,8 +1,11 @@
 package fs
 
 import (
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -102,6 +105,30 @@ func TestMove(t *testing.T) {
 	}
 }
 
+func TestProgressReader(t *testing.T) {
+	var copied int64
+	var reports []int64
+	r := &progressReader{
+		r:        strings.NewReader("hello world"),
+		copied:   &copied,
+		progress: func(n int64) { reports = append(reports, n) },
+	}
+
+	buf := make([]byte, 4)
+	for {
+		if _, err := r.Read(buf); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+	}
+
+	want := []int64{4, 8, 11}
+	if !slices.Equal(reports, want) {
+		t.Errorf("progress reports = %v, want %v", reports, want)
+	}
+}
+
 func TestCreateWithBackup(t *testing.T) {
 	dir := createTempDir(t)
 	originalPath := filepath.Join(dir, "original.txt")
@@ -0,0 +1,16 @@
+package fs
+
+import "time"
+
+// Attrs holds the attributes of a file that os.FileInfo does not carry
+type Attrs struct {
+	// UID and GID own the file. They are -1 where unknown.
+	UID int
+	GID int
+
+	// AccessTime is when the file was last read, or zero where unknown
+	AccessTime time.Time
+
+	// Xattrs reports whether the file has extended attributes
+	Xattrs bool
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd && !netbsd
+
+package fs
+
+import "os"
+
+// ReadAttrs returns the attributes of path. Symbolic links are not
+// followed. Ownership, access times and extended attributes are unknown
+// on this platform.
+func ReadAttrs(path string) (Attrs, error) {
+	attrs := Attrs{UID: -1, GID: -1}
+	_, err := os.Lstat(path)
+	return attrs, err
+}
+
+// allocated returns the disk space allocated to the file of info, which
+// is approximated by its size on this platform
+func allocated(info os.FileInfo) int64 {
+	if info.IsDir() {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,31 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestReadAttrs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix-specific test")
+	}
+	file := filepath.Join(createTempDir(t), "file.txt")
+	createTestFile(t, file, "content")
+
+	attrs, err := ReadAttrs(file)
+	if err != nil {
+		t.Fatalf("ReadAttrs() error = %v", err)
+	}
+	if attrs.UID != os.Getuid() || attrs.GID != os.Getgid() {
+		t.Errorf("owner = %d:%d, want %d:%d", attrs.UID, attrs.GID, os.Getuid(), os.Getgid())
+	}
+	if attrs.AccessTime.IsZero() {
+		t.Error("AccessTime should be known")
+	}
+
+	if _, err := ReadAttrs(filepath.Join(file, "missing")); err == nil {
+		t.Error("ReadAttrs() should fail for a missing file")
+	}
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || netbsd
+
+package fs
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReadAttrs returns the attributes of path. Symbolic links are not followed.
+func ReadAttrs(path string) (Attrs, error) {
+	var stat unix.Stat_t
+	if err := unix.Lstat(path, &stat); err != nil {
+		return Attrs{UID: -1, GID: -1}, err
+	}
+	attrs := Attrs{
+		UID:        int(stat.Uid),
+		GID:        int(stat.Gid),
+		AccessTime: time.Unix(stat.Atim.Unix()),
+	}
+	// With an empty buffer, the size of the list of names is returned
+	if size, err := unix.Llistxattr(path, nil); err == nil && size > 0 {
+		attrs.Xattrs = true
+	}
+	return attrs, nil
+}
+
+// allocated returns the disk space allocated to the file of info
+func allocated(info os.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(stat.Blocks) * 512
+	}
+	return info.Size()
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// SameDevice reports whether two paths are on the same file system.
+// Symbolic links are not followed.
+func SameDevice(path1, path2 string) (bool, error) {
+	dev1, err := device(path1)
+	if err != nil {
+		return false, err
+	}
+	dev2, err := device(path2)
+	if err != nil {
+		return false, err
+	}
+	return dev1 == dev2, nil
+}
+
+func device(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("failed to get device information of %s", path)
+	}
+	return uint64(stat.Dev), nil // Dev is int32 on darwin
+}
@@ -0,0 +1,20 @@
+package fs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSameDevice(t *testing.T) {
+	dir := createTempDir(t)
+	file := filepath.Join(dir, "file.txt")
+	createTestFile(t, file, "content")
+
+	same, err := SameDevice(dir, file)
+	if err != nil {
+		t.Fatalf("SameDevice() error = %v", err)
+	}
+	if !same {
+		t.Error("a file and its directory should be on the same device")
+	}
+}
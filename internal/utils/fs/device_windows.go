@@ -0,0 +1,21 @@
+//go:build windows
+
+package fs
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SameDevice reports whether two paths are on the same volume
+func SameDevice(path1, path2 string) (bool, error) {
+	abs1, err := filepath.Abs(path1)
+	if err != nil {
+		return false, err
+	}
+	abs2, err := filepath.Abs(path2)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(filepath.VolumeName(abs1), filepath.VolumeName(abs2)), nil
+}
@@ -48,3 +48,25 @@ func DirSize(path string) (int64, error) {
 
 	return size, nil
 }
+
+// DiskUsage returns the disk space used by path in bytes, as "du -B1"
+// reports it: the blocks allocated to path and, for a directory, to
+// everything under it. Symbolic links are not followed.
+func DiskUsage(path string) (int64, error) {
+	var usage int64
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		usage += allocated(info)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return usage, nil
+}
@@ -3,6 +3,7 @@ package fs
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -108,3 +109,22 @@ func TestDirSize_RegularFile(t *testing.T) {
 		t.Errorf("DirSize() = %d, want %d", size, len(content))
 	}
 }
+
+func TestDiskUsage(t *testing.T) {
+	dir := createTempDir(t)
+	createTestFile(t, filepath.Join(dir, "a.txt"), strings.Repeat("a", 10000))
+	createTestFile(t, filepath.Join(dir, "b.txt"), "b")
+
+	usage, err := DiskUsage(dir)
+	if err != nil {
+		t.Fatalf("DiskUsage() error = %v", err)
+	}
+	// Files take whole blocks, so usage is at least the apparent size
+	if usage < 10001 {
+		t.Errorf("DiskUsage() = %d, want at least 10001", usage)
+	}
+
+	if _, err := DiskUsage(filepath.Join(dir, "missing")); err == nil {
+		t.Error("DiskUsage() expected error for non-existent path, got nil")
+	}
+}
@@ -2,16 +2,30 @@ package shell
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 func RunCommand(input string) (string, int, error) {
-	cmd := exec.Command("bash", "-c", input)
+	return RunCommandContext(context.Background(), input, nil)
+}
+
+// RunCommandContext runs input like RunCommand, with env added to the
+// environment. The command is killed when ctx is done, in which case the
+// error of ctx is returned.
+func RunCommandContext(ctx context.Context, input string, env []string) (string, int, error) {
+	cmd := exec.CommandContext(ctx, "bash", "-c", input)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	// Do not wait for background processes holding the output open
+	cmd.WaitDelay = time.Second
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -27,6 +41,9 @@ func RunCommand(input string) (string, int, error) {
 	if err == nil {
 		return output, 0, nil
 	}
+	if ctx.Err() != nil {
+		return output, -1, ctx.Err()
+	}
 	var ee *exec.ExitError
 	if !errors.As(err, &ee) {
 		return output, -1, err
@@ -1,8 +1,11 @@
 package shell
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestRunCommand(t *testing.T) {
@@ -49,6 +52,24 @@ func TestRunCommand(t *testing.T) {
 	}
 }
 
+func TestRunCommandContext(t *testing.T) {
+	output, code, err := RunCommandContext(context.Background(), `printf %s "$GOMI_TEST"`, []string{"GOMI_TEST=hello"})
+	if err != nil || code != 0 || output != "hello" {
+		t.Errorf("RunCommandContext() = %q, %d, %v, want %q, 0, nil", output, code, err, "hello")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, code, err = RunCommandContext(ctx, "sleep 5", nil)
+	if !errors.Is(err, context.DeadlineExceeded) || code != -1 {
+		t.Errorf("RunCommandContext() = %d, %v, want -1, %v", code, err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("command was not killed at the deadline (took %v)", elapsed)
+	}
+}
+
 func TestExpandHome(t *testing.T) {
 	// Set a test home directory
 	testHome := "/test/home/user"
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -24,7 +25,9 @@ func main() {
 	})
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", appName, err)
-		os.Exit(1)
+		if !errors.Is(err, cli.ErrReported) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", appName, err)
+		}
+		os.Exit(cli.ExitCode(err))
 	}
 }